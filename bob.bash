@@ -40,7 +40,7 @@ fi
 
 # Get a hash of the environment so we can detect if we need to
 # regenerate the build.ninja
-python3 "${BOB_DIR}/scripts/env_hash.py" "${BUILDDIR}/.env.hash"
+python3 "${BOB_DIR}/scripts/env_hash.py" "${BUILDDIR}/.env.hash" --config-json "${CONFIG_JSON}"
 
 # If enabled, the following environment variables optimize the performance
 # of ccache. Otherwise they have no effect.
@@ -0,0 +1,41 @@
+#!/bin/bash
+
+set -e
+
+# Checks the build definitions without generating the build, e.g. from a
+# pre-commit hook. All warning categories are reported unless filtered by
+# BOB_LINT_WARNINGS, or else BOB_LOG_WARNINGS. The exit code is non-zero on
+# errors.
+#
+# Example usage
+# ./bob_lint
+#
+# To only report relative up-links as errors
+# BOB_LINT_WARNINGS="*:I RelativeUpLinkWarning:E" ./bob_lint
+
+# Switch to the build directory
+cd "$(dirname "${BASH_SOURCE[0]}")"
+
+# Read settings written by bootstrap.bash
+source ".bob.bootstrap"
+
+# Allow overriding the filters set when bootstrapping
+[[ -n "${BOB_LINT_WARNINGS-}" ]] && export BOB_LOG_WARNINGS="${BOB_LINT_WARNINGS}"
+
+# Switch to the working directory
+cd -P "${WORKDIR}"
+
+BOB_BUILDER_TARGET=".bootstrap/bin/bob"
+BOB_BUILDER="${BUILDDIR}/${BOB_BUILDER_TARGET}"
+BOB_BUILDER_NINJA="${BUILDDIR}/.bootstrap/build.ninja"
+
+if [ ! -f "${BOB_BUILDER_NINJA}" ]; then
+	echo "Missing ${BOB_BUILDER_NINJA}"
+	echo "Please build your project first"
+	exit 1
+fi
+
+# Make sure Bob is built
+ninja -f "${BOB_BUILDER_NINJA}" "${BOB_BUILDER_TARGET}" > /dev/null
+
+"${BOB_BUILDER}" lint -l "${BLUEPRINT_LIST_FILE}" -b "${BUILDDIR}" "$@" "${SRCDIR}/${TOPNAME}"
@@ -119,8 +119,11 @@ BOB_DIR="$(relative_path "$(pwd)" "${SCRIPT_DIR}")"
 CONFIG_FILE="${CONFIGDIR}/${CONFIGNAME}"
 CONFIG_JSON="${CONFIGDIR}/.bob.config.json"
 
+# Bob warnings log format, one of csv, jsonl or sarif
+BOB_LOG_WARNINGS_FORMAT="${BOB_LOG_WARNINGS_FORMAT:-csv}"
+
 # Bob warnings log file
-BOB_LOG_WARNINGS_FILE="${BUILDDIR}/.bob.warnings.csv"
+BOB_LOG_WARNINGS_FILE="${BUILDDIR}/.bob.warnings.${BOB_LOG_WARNINGS_FORMAT}"
 
 # space separated values, e.g. "*:W RelativeUpLinkWarning:E"
 BOB_LOG_WARNINGS=""
@@ -130,6 +133,7 @@ export CONFIG_FILE
 export CONFIG_JSON
 export BOB_LOG_WARNINGS
 export BOB_LOG_WARNINGS_FILE
+export BOB_LOG_WARNINGS_FORMAT
 export TOPNAME="build.bp"
 export BOOTSTRAP="${BOB_DIR}/bootstrap.bash"
 export BLUEPRINTDIR="${BOB_DIR}/blueprint"
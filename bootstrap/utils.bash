@@ -26,7 +26,9 @@ function write_bootstrap() {
         -e "s|@@BobConfigPluginOpts@@|${BOB_CONFIG_PLUGIN_OPTS}|" \
         -e "s|@@BobBootstrapVersion@@|${BOB_VERSION}|" \
         -e "s|@@BobLogWarningsFile@@|${BOB_LOG_WARNINGS_FILE}|" \
+        -e "s|@@BobLogWarningsFormat@@|${BOB_LOG_WARNINGS_FORMAT}|" \
         -e "s|@@BobMetaFile@@|${BOB_META_FILE}|" \
+        -e "s|@@BobCompileCommandsFile@@|${BOB_COMPILE_COMMANDS_FILE}|" \
         -e "s|@@BobLogWarnings@@|${BOB_LOG_WARNINGS}|" \
         "${BOB_DIR}/bob.bootstrap.in" > "${BUILDDIR}/.bob.bootstrap.tmp"
     rsync -c "${BUILDDIR}/.bob.bootstrap.tmp" "${BUILDDIR}/.bob.bootstrap"
@@ -47,4 +49,5 @@ function create_bob_symlinks() {
 
     ln -sf "${BOB_DIR}/bob.bash" "${BUILDDIR}/bob"
     ln -sf "${BOB_DIR}/bob_graph.bash" "${BUILDDIR}/bob_graph"
+    ln -sf "${BOB_DIR}/bob_lint.bash" "${BUILDDIR}/bob_lint"
 }
@@ -11,6 +11,19 @@ import (
 )
 
 func main() {
+	// Subcommands run by the generated build, rather than by Blueprint
+	if len(os.Args) > 1 && os.Args[1] == "package" {
+		packageMain(os.Args[2:])
+		return
+	}
+
+	// Checks the module definitions, taking the same arguments as the
+	// primary builder, without generating a build
+	lint := len(os.Args) > 1 && os.Args[1] == "lint"
+	if lint {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// The primary builder should use the global flag set because the
 	// bootstrap package registers its own flags there.
 	flag.Parse()
@@ -25,7 +38,11 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
-	core.Main()
+	if lint {
+		core.Lint()
+	} else {
+		core.Main()
+	}
 
 	memprofile, present := os.LookupEnv("BOB_MEMPROFILE")
 	if present && memprofile != "" {
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ARM-software/bob-build/internal/archive"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// Parses the installed files listed in `file`. Each entry is the path of
+// the file, followed by its mode, owner and group, separated by colons.
+func readEntries(file, root string) []archive.Entry {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		utils.Die("%v", err)
+	}
+
+	entries := []archive.Entry{}
+	for _, s := range strings.Fields(string(content)) {
+		fields := strings.Split(s, ":")
+		if len(fields) < 4 {
+			utils.Die("Invalid entry %s in %s", s, file)
+		}
+		n := len(fields)
+		path := strings.Join(fields[:n-3], ":")
+
+		name, err := filepath.Rel(root, path)
+		if err != nil || strings.HasPrefix(name, "..") {
+			utils.Die("%s is not installed in %s", path, root)
+		}
+
+		mode := int64(-1)
+		if fields[n-3] != "" {
+			mode, err = strconv.ParseInt(fields[n-3], 8, 32)
+			if err != nil {
+				utils.Die("Invalid mode %s for %s", fields[n-3], path)
+			}
+		}
+
+		entries = append(entries, archive.Entry{
+			Name:   filepath.ToSlash(name),
+			Source: path,
+			Mode:   mode,
+			Owner:  fields[n-2],
+			Group:  fields[n-1],
+		})
+	}
+	return entries
+}
+
+// Implements `bob package`, which writes an archive of installed files.
+// This is run by the build, rather than by users.
+func packageMain(args []string) {
+	fs := flag.NewFlagSet("package", flag.ExitOnError)
+	format := fs.String("format", "tar", "Archive format, `tar` or `deb`")
+	root := fs.String("root", "", "Directory the archive is relative to")
+	entriesFile := fs.String("entries", "", "File listing the installed files")
+	output := fs.String("o", "", "Output file")
+	mtime := fs.Int64("mtime", 0, "Modification time of every file, in seconds since the epoch")
+
+	var control archive.Control
+	fs.StringVar(&control.Package, "package", "", "Name of the Debian package")
+	fs.StringVar(&control.Version, "version", "", "Version of the package")
+	fs.StringVar(&control.Architecture, "architecture", "", "Debian architecture")
+	fs.StringVar(&control.Maintainer, "maintainer", "", "Maintainer of the package")
+	fs.StringVar(&control.Description, "description", "", "Description of the package")
+	fs.StringVar(&control.Section, "section", "", "Debian section")
+	fs.StringVar(&control.Priority, "priority", "", "Debian priority")
+	fs.Var((*stringList)(&control.Depends), "depends", "Dependency of the package")
+	fs.Parse(args)
+
+	if *root == "" || *entriesFile == "" || *output == "" {
+		utils.Die("bob package requires --root, --entries and -o")
+	}
+
+	entries := readEntries(*entriesFile, *root)
+
+	if err := os.MkdirAll(filepath.Dir(*output), 0755); err != nil {
+		utils.Die("%v", err)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		utils.Die("%v", err)
+	}
+
+	switch *format {
+	case "tar":
+		err = archive.WriteTar(f, entries, time.Unix(*mtime, 0))
+	case "deb":
+		err = archive.WriteDeb(f, control, entries, time.Unix(*mtime, 0))
+	default:
+		err = fmt.Errorf("unsupported format %s", *format)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*output)
+		utils.Die("error writing %s: %v", *output, err)
+	}
+}
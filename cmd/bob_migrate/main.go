@@ -0,0 +1,77 @@
+// bob_migrate rewrites the legacy modules of .bp files to the strict module
+// types, formatting the files as bpfmt does. The module definitions it
+// cannot convert are left unchanged and reported.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/blueprint/parser"
+
+	"github.com/ARM-software/bob-build/internal/migrate"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+func main() {
+	write := flag.Bool("w", false, "Write the result to the files rather than to stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-w] file.bp...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// All the files are migrated together, as the users of defaults may be
+	// in other files.
+	files := []*parser.File{}
+	original := map[*parser.File][]byte{}
+
+	for _, path := range flag.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			utils.Die("%v", err)
+		}
+
+		f, errs := parser.Parse(path, bytes.NewReader(content), parser.NewScope(nil))
+		if len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			utils.Die("Failed to parse %s", path)
+		}
+
+		files = append(files, f)
+		original[f] = content
+	}
+
+	issues := migrate.Migrate(files)
+
+	for _, f := range files {
+		out, err := parser.Print(f)
+		if err != nil {
+			utils.Die("Failed to format %s: %v", f.Name, err)
+		}
+
+		if !*write {
+			os.Stdout.Write(out)
+		} else if !bytes.Equal(out, original[f]) {
+			if err := os.WriteFile(f.Name, out, 0666); err != nil {
+				utils.Die("%v", err)
+			}
+		}
+	}
+
+	for _, d := range issues {
+		fmt.Fprintln(os.Stderr, d.String())
+	}
+	if len(issues) > 0 {
+		utils.Die("%d issue(s) prevented modules from being migrated", len(issues))
+	}
+}
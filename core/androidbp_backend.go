@@ -46,6 +46,11 @@ func (*androidBpGenerator) kernelModuleActions(m *ModuleKernelObject, ctx bluepr
 	}
 }
 
+// packageActions implements generatorBackend.
+func (*androidBpGenerator) packageActions(m *ModulePackage, ctx blueprint.ModuleContext) {
+	// Android builds its own images, so packages are not produced.
+}
+
 // Translate `bob_alias` to `phony` rules in Android.bp
 func (g *androidBpGenerator) aliasActions(a *ModuleAlias, ctx blueprint.ModuleContext) {
 	srcs := []string{}
@@ -130,7 +130,26 @@ func (m *ModuleLibrary) getGeneratedHeaderModules(ctx blueprint.BaseModuleContex
 	return
 }
 
-func addMTEProps(m bpwriter.Module, props AndroidMTEProps) {
+// Soong accepts a single `sanitize` group per module, which holds the
+// sanitizer, MTE and HWASAN properties. It is only added to the module once
+// one of them is set.
+type androidSanitizeGroup struct {
+	mod   bpwriter.Module
+	group bpwriter.Group
+}
+
+func newAndroidSanitizeGroup(mod bpwriter.Module) *androidSanitizeGroup {
+	return &androidSanitizeGroup{mod: mod}
+}
+
+func (s *androidSanitizeGroup) get() bpwriter.Group {
+	if s.group == nil {
+		s.group = s.mod.NewGroup("sanitize")
+	}
+	return s.group
+}
+
+func addMTEProps(sanitize *androidSanitizeGroup, props AndroidMTEProps) {
 	memtagHeap := proptools.Bool(props.Mte.Memtag_heap)
 	diagMemtagHeap := proptools.Bool(props.Mte.Diag_memtag_heap)
 
@@ -138,7 +157,7 @@ func addMTEProps(m bpwriter.Module, props AndroidMTEProps) {
 		return
 	}
 
-	g := m.NewGroup("sanitize")
+	g := sanitize.get()
 	g.AddBool("memtag_heap", true)
 
 	if diagMemtagHeap {
@@ -147,9 +166,39 @@ func addMTEProps(m bpwriter.Module, props AndroidMTEProps) {
 	}
 }
 
-func addHWASANProps(m bpwriter.Module) {
-	g := m.NewGroup("sanitize")
-	g.AddBool("hwaddress", true)
+func addSanitizeProps(sanitize *androidSanitizeGroup, props *SanitizeProps, ctx blueprint.ModuleContext) {
+	s := &props.Sanitize
+	if proptools.Bool(s.Memory) {
+		propertyErrorf(ctx, "sanitize", "sanitize.memory is not supported by the Android backend")
+	}
+
+	if s.Address == nil && s.Undefined == nil && s.Thread == nil &&
+		len(s.Misc_undefined) == 0 {
+		return
+	}
+
+	g := sanitize.get()
+	g.AddOptionalBool("address", s.Address)
+	g.AddOptionalBool("undefined", s.Undefined)
+	g.AddOptionalBool("thread", s.Thread)
+	g.AddStringList("misc_undefined", s.Misc_undefined)
+}
+
+func addTidyProps(m bpwriter.Module, props *TidyProps) {
+	m.AddOptionalBool("tidy", props.Tidy)
+	m.AddStringList("tidy_checks", props.Tidy_checks)
+	m.AddStringList("tidy_checks_as_errors", props.Tidy_checks_as_errors)
+	m.AddStringList("tidy_flags", props.Tidy_flags)
+}
+
+// Soong only instruments modules when coverage is enabled in the build, so
+// the property is passed as is.
+func addCoverageProps(m bpwriter.Module, props *CoverageProps) {
+	m.AddOptionalBool("native_coverage", props.Coverage)
+}
+
+func addHWASANProps(sanitize *androidSanitizeGroup) {
+	sanitize.get().AddBool("hwaddress", true)
 }
 
 func addRequiredModules(mod bpwriter.Module, m ModuleLibrary, ctx blueprint.ModuleContext) {
@@ -191,7 +240,7 @@ func (g *androidBpGenerator) getVersionScript(m *ModuleLibrary, ctx blueprint.Mo
 	return m.Properties.Build.Version_script
 }
 
-func addCcLibraryProps(mod bpwriter.Module, m ModuleLibrary, ctx blueprint.ModuleContext) {
+func addCcLibraryProps(mod bpwriter.Module, sanitize *androidSanitizeGroup, m ModuleLibrary, ctx blueprint.ModuleContext) {
 	if len(m.Properties.Export_include_dirs) > 0 {
 		utils.Die("Module %s exports non-local include dirs %v - this is not supported",
 			ctx.ModuleName(), m.Properties.Export_include_dirs)
@@ -281,6 +330,8 @@ func addCcLibraryProps(mod bpwriter.Module, m ModuleLibrary, ctx blueprint.Modul
 	mod.AddStringList("export_static_lib_headers", reexportStatic)
 	mod.AddStringList("export_header_lib_headers", reexportHeaders)
 	mod.AddStringList("ldflags", utils.Filter(ccflags.AndroidLinkFlags, m.Properties.Ldflags))
+	addSanitizeProps(sanitize, &m.Properties.SanitizeProps, ctx)
+	addCoverageProps(mod, &m.Properties.CoverageProps)
 
 	_, installRel, ok := getSoongInstallPath(m.getInstallableProps())
 	if ok && installRel != "" {
@@ -298,7 +349,7 @@ func addCcLibraryProps(mod bpwriter.Module, m ModuleLibrary, ctx blueprint.Modul
 	}
 }
 
-func addBinaryProps(mod bpwriter.Module, m *ModuleBinary, ctx blueprint.ModuleContext, g *androidBpGenerator) {
+func addBinaryProps(mod bpwriter.Module, sanitize *androidSanitizeGroup, m *ModuleBinary, ctx blueprint.ModuleContext, g *androidBpGenerator) {
 	// Handle installation
 	if _, installRel, ok := getSoongInstallPath(m.getInstallableProps()); ok {
 		// Only setup multilib for target modules.
@@ -323,9 +374,9 @@ func addBinaryProps(mod bpwriter.Module, m *ModuleBinary, ctx blueprint.ModuleCo
 
 	bc := GetModuleBackendConfiguration(ctx, m)
 	if bc != nil {
-		addMTEProps(mod, bc.GetMteProps(ctx))
+		addMTEProps(sanitize, bc.GetMteProps(ctx))
 		if !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-			addHWASANProps(mod)
+			addHWASANProps(sanitize)
 		}
 	}
 }
@@ -354,7 +405,7 @@ func addStripProp(m bpwriter.Module) {
 	g.AddBool("all", true)
 }
 
-func addCompilableProps(mod bpwriter.Module, m Compilable, ctx blueprint.ModuleContext) {
+func addCompilableProps(mod bpwriter.Module, sanitize *androidSanitizeGroup, m Compilable, ctx blueprint.ModuleContext) {
 	// TODO: move this check to the module itself
 	// if len(m.Properties.Export_include_dirs) > 0 {
 	// 	utils.Die("Module %s exports non-local include dirs %v - this is not supported",
@@ -465,7 +516,8 @@ func addCompilableProps(mod bpwriter.Module, m Compilable, ctx blueprint.ModuleC
 
 	bc := GetModuleBackendConfiguration(ctx, m)
 	if bc != nil {
-		addMTEProps(mod, bc.GetMteProps(ctx))
+		addMTEProps(sanitize, bc.GetMteProps(ctx))
+		addSanitizeProps(sanitize, bc.getSanitizeProps(), ctx)
 	}
 
 	if std := ccflags.GetCompilerStandard(cflags, conlyFlags); std != "" {
@@ -508,6 +560,14 @@ func addCompilableProps(mod bpwriter.Module, m Compilable, ctx blueprint.ModuleC
 	mod.AddStringList("shared_libs", shared_libs)
 	mod.AddStringList("static_libs", static_libs)
 	mod.AddStringList("whole_static_libs", whole_static_libs)
+
+	if t, ok := m.(tidyable); ok {
+		addTidyProps(mod, t.getTidyProps())
+	}
+
+	if c, ok := m.(coverable); ok {
+		addCoverageProps(mod, c.getCoverageProps())
+	}
 }
 
 func (g *androidBpGenerator) binaryActions(m *ModuleBinary, ctx blueprint.ModuleContext) {
@@ -545,9 +605,10 @@ func (g *androidBpGenerator) binaryActions(m *ModuleBinary, ctx blueprint.Module
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 
-	addCcLibraryProps(mod, m.ModuleLibrary, ctx)
-	addBinaryProps(mod, m, ctx, g)
+	addCcLibraryProps(mod, sanitize, m.ModuleLibrary, ctx)
+	addBinaryProps(mod, sanitize, m, ctx, g)
 	bc := GetModuleBackendConfiguration(ctx, m)
 	if bc.strip() {
 		addStripProp(mod)
@@ -590,8 +651,9 @@ func (g *androidBpGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 
-	addCcLibraryProps(mod, m.ModuleLibrary, ctx)
+	addCcLibraryProps(mod, sanitize, m.ModuleLibrary, ctx)
 	addStaticOrSharedLibraryProps(mod, m.ModuleLibrary, ctx)
 
 	bc := GetModuleBackendConfiguration(ctx, m)
@@ -600,7 +662,7 @@ func (g *androidBpGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint
 	}
 
 	if !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-		addHWASANProps(mod)
+		addHWASANProps(sanitize)
 	}
 
 	versionScript := g.getVersionScript(&m.ModuleLibrary, ctx)
@@ -626,14 +688,15 @@ func (g *androidBpGenerator) staticActions(m *ModuleStaticLibrary, ctx blueprint
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 
-	addCcLibraryProps(mod, m.ModuleLibrary, ctx)
+	addCcLibraryProps(mod, sanitize, m.ModuleLibrary, ctx)
 	addStaticOrSharedLibraryProps(mod, m.ModuleLibrary, ctx)
 
 	bc := GetModuleBackendConfiguration(ctx, m)
 	if bc != nil {
 		if !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-			addHWASANProps(mod)
+			addHWASANProps(sanitize)
 		}
 	}
 }
@@ -658,6 +721,7 @@ func (g *androidBpGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx bl
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 
 	bc := GetModuleBackendConfiguration(ctx, m)
 
@@ -671,7 +735,7 @@ func (g *androidBpGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx bl
 		mod.AddBool("device_supported", true)
 	}
 
-	addCompilableProps(mod, m, ctx)
+	addCompilableProps(mod, sanitize, m, ctx)
 
 	if bc != nil && bc.strip() {
 		addStripProp(mod)
@@ -686,7 +750,7 @@ func (g *androidBpGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx bl
 	addProvenanceProps(ctx, mod, m)
 
 	if bc != nil && !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-		addHWASANProps(mod)
+		addHWASANProps(sanitize)
 	}
 
 	// TODO: Make addRequiredModules generic and enable it if needed
@@ -717,10 +781,11 @@ func (g *androidBpGenerator) executableTestActions(m *ModuleTest, ctx blueprint.
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 
 	bc := GetModuleBackendConfiguration(ctx, m)
 
-	addCompilableProps(mod, m, ctx)
+	addCompilableProps(mod, sanitize, m, ctx)
 
 	if bc != nil && bc.strip() {
 		addStripProp(mod)
@@ -736,7 +801,7 @@ func (g *androidBpGenerator) executableTestActions(m *ModuleTest, ctx blueprint.
 	addProvenanceProps(ctx, mod, m)
 
 	if bc != nil && !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-		addHWASANProps(mod)
+		addHWASANProps(sanitize)
 	}
 
 	// Avoid using cc_test default setup
@@ -745,6 +810,8 @@ func (g *androidBpGenerator) executableTestActions(m *ModuleTest, ctx blueprint.
 	mod.AddBool("include_build_directory", false)
 	mod.AddBool("auto_gen_config", false)
 	mod.AddBool("gtest", false)
+
+	addTestProps(ctx, mod, m)
 }
 
 func (g *androidBpGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blueprint.ModuleContext) {
@@ -764,16 +831,17 @@ func (g *androidBpGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blue
 	if err != nil {
 		panic(err.Error())
 	}
+	sanitize := newAndroidSanitizeGroup(mod)
 	bc := GetModuleBackendConfiguration(ctx, m)
 
-	addCompilableProps(mod, m, ctx)
+	addCompilableProps(mod, sanitize, m, ctx)
 
 	if bc != nil && bc.strip() {
 		addStripProp(mod)
 	}
 
 	if bc != nil && !proptools.Bool(bc.GetMteProps(ctx).Mte.Diag_memtag_heap) && bc.IsHwAsanEnabled() {
-		addHWASANProps(mod)
+		addHWASANProps(sanitize)
 	}
 
 	if m.Properties.TargetType == toolchain.TgtTypeTarget &&
@@ -88,3 +88,22 @@ func Test_addCFlags4(t *testing.T) {
 
 	assert.Equal(t, err.Error(), "Both thumb and no thumb (arm) options are specified")
 }
+
+func Test_testCommandLines(t *testing.T) {
+	shards := 2
+	props := &TestProps{
+		Args:        []string{"--verbose"},
+		Env:         []string{"MODE=fast"},
+		Shard_count: &shards,
+	}
+
+	keys, cmds := testCommandLines("test_a", props)
+	assert.Equal(t, []string{"test_a_shard_0_of_2", "test_a_shard_1_of_2"}, keys)
+	assert.Equal(t, "cd /data/local/tmp/test_a && MODE=fast TEST_TOTAL_SHARDS=2 TEST_SHARD_INDEX=1 "+
+		"GTEST_TOTAL_SHARDS=2 GTEST_SHARD_INDEX=1 ./test_a --verbose", cmds[1])
+}
+
+func Test_writeLinesCmd(t *testing.T) {
+	assert.Equal(t, `for l in '<a b="it'"'"'s">' '$$HOME'; do echo "$$l"; done > $(out)`,
+		writeLinesCmd([]string{`<a b="it's">`, "$HOME"}))
+}
@@ -0,0 +1,159 @@
+package core
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/ARM-software/bob-build/internal/bpwriter"
+)
+
+// Directory on the device which target tests are pushed to and run from
+const androidTestDir = "/data/local/tmp"
+
+// Soong takes the arguments, environment, timeout and sharding of a test
+// from its Tradefed config. Bob tests are not GoogleTest binaries, so when
+// any of these is set the test is run by Tradefed's ExecutableTargetTest,
+// with a config generated by a genrule.
+func addTestProps(ctx blueprint.ModuleContext, mod bpwriter.Module, m *ModuleTest) {
+	props := &m.TestProperties.TestProps
+
+	mod.AddStringList("data", props.Data)
+
+	if proptools.Bool(props.Flaky) {
+		mod.NewGroup("test_options").AddStringList("tags", []string{"flaky"})
+	}
+
+	if !needsTestConfig(props) {
+		return
+	}
+
+	if m.Properties.TargetType == toolchain.TgtTypeHost {
+		// Host tests are run by a different Tradefed runner, which does
+		// not support a command line per test
+		for _, prop := range []string{"args", "env", "timeout", "shard_count", "size"} {
+			if testPropertySet(props, prop) {
+				propertyErrorf(ctx, prop, "is not supported by host tests on Android")
+			}
+		}
+		return
+	}
+
+	name := m.shortName() + "_test_config"
+	config, err := AndroidBpFile().NewModule("genrule", name)
+	if err != nil {
+		panic(err)
+	}
+	out := m.shortName() + ".xml"
+	config.AddStringList("out", []string{out})
+	config.AddString("cmd", writeLinesCmd(tradefedTestConfig(m.shortName(), props)))
+
+	mod.AddString("test_config", ":"+name)
+}
+
+func needsTestConfig(props *TestProps) bool {
+	return len(props.Args) > 0 || len(props.Env) > 0 || props.timeout() > 0 || props.shardCount() > 1
+}
+
+func testPropertySet(props *TestProps, prop string) bool {
+	switch prop {
+	case "args":
+		return len(props.Args) > 0
+	case "env":
+		return len(props.Env) > 0
+	case "timeout":
+		return props.Timeout != nil
+	case "shard_count":
+		return props.shardCount() > 1
+	case "size":
+		return props.Size != nil
+	}
+	return false
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// testCommandLines returns the shell command running each shard of a test
+// on the device, keyed by the name of its results.
+func testCommandLines(name string, props *TestProps) (keys []string, cmds []string) {
+	env := []string{}
+	for _, e := range props.Env {
+		k, v, _ := strings.Cut(e, "=")
+		env = append(env, k+"="+proptools.ShellEscape(v))
+	}
+	args := proptools.ShellEscapeList(props.Args)
+
+	count := props.shardCount()
+	for i := 0; i < count; i++ {
+		key := name
+		shardEnv := env
+		if count > 1 {
+			key = fmt.Sprintf("%s_shard_%d_of_%d", name, i, count)
+			shardEnv = append(append([]string{}, env...),
+				fmt.Sprintf("TEST_TOTAL_SHARDS=%d", count), fmt.Sprintf("TEST_SHARD_INDEX=%d", i),
+				fmt.Sprintf("GTEST_TOTAL_SHARDS=%d", count), fmt.Sprintf("GTEST_SHARD_INDEX=%d", i))
+		}
+
+		cmd := []string{"cd", path.Join(androidTestDir, name), "&&"}
+		cmd = append(cmd, shardEnv...)
+		cmd = append(cmd, "./"+name)
+		cmd = append(cmd, args...)
+
+		keys = append(keys, key)
+		cmds = append(cmds, strings.Join(cmd, " "))
+	}
+	return
+}
+
+// tradefedTestConfig returns the lines of a Tradefed config which pushes a
+// test and its data to the device, and runs it.
+func tradefedTestConfig(name string, props *TestProps) []string {
+	dir := path.Join(androidTestDir, name)
+	lines := []string{
+		`<?xml version="1.0" encoding="utf-8"?>`,
+		`<!-- Generated by Bob -->`,
+		fmt.Sprintf(`<configuration description="Runs %s.">`, xmlEscape(name)),
+		`    <target_preparer class="com.android.tradefed.targetprep.PushFilePreparer">`,
+		`        <option name="cleanup" value="true" />`,
+	}
+	for _, f := range append([]string{name}, props.Data...) {
+		lines = append(lines, fmt.Sprintf(`        <option name="push-file" key="%s" value="%s" />`,
+			xmlEscape(f), xmlEscape(path.Join(dir, f))))
+	}
+	lines = append(lines,
+		`    </target_preparer>`,
+		`    <test class="com.android.tradefed.testtype.binary.ExecutableTargetTest">`)
+	if timeout := props.timeout(); timeout > 0 {
+		lines = append(lines, fmt.Sprintf(`        <option name="per-binary-timeout" value="%ds" />`, timeout))
+	}
+	keys, cmds := testCommandLines(name, props)
+	for i := range keys {
+		lines = append(lines, fmt.Sprintf(`        <option name="test-command-line" key="%s" value="%s" />`,
+			xmlEscape(keys[i]), xmlEscape(cmds[i])))
+	}
+	lines = append(lines,
+		`    </test>`,
+		`</configuration>`)
+	return lines
+}
+
+// writeLinesCmd returns a genrule command writing the lines to its output.
+// The command avoids backslashes, which are not escaped in Android.bp.
+func writeLinesCmd(lines []string) string {
+	cmd := []string{"for", "l", "in"}
+	for _, l := range lines {
+		quoted := "'" + strings.ReplaceAll(l, "'", `'"'"'`) + "'"
+		// `$` introduces a variable in genrule commands
+		cmd = append(cmd, strings.ReplaceAll(quoted, "$", "$$"))
+	}
+	return strings.Join(cmd, " ") + `; do echo "$$l"; done > $(out)`
+}
@@ -422,7 +422,7 @@ func (g *androidNinjaGenerator) ObjDir(m Compilable) string {
 
 // executableTestActions implements generatorBackend.
 func (*androidNinjaGenerator) executableTestActions(m *ModuleTest, ctx blueprint.ModuleContext) {
-	GetLogger().Warn(warnings.AndroidOutOfTreeUnsupportedModule, ctx.BlueprintsFile(), ctx.ModuleName())
+	warnModule(ctx, warnings.AndroidOutOfTreeUnsupportedModule)
 }
 
 // filegroupActions implements generatorBackend.
@@ -682,9 +682,7 @@ func (g *androidNinjaGenerator) install(m interface{}, ctx blueprint.ModuleConte
 								}
 								if separateDebugInfo {
 									// TODO: This should really be using file interface when enabled
-									dbgFile := filepath.Join(debugPathPrefix, basename+".dbg")
-									stArgs = append(stArgs, "--debug-file")
-									stArgs = append(stArgs, dbgFile)
+									stArgs = append(stArgs, debugInfoArgs(lib, debugPathPrefix, basename)...)
 								}
 								stripArgs := map[string]string{
 									"args": strings.Join(stArgs, " "),
@@ -784,6 +782,11 @@ func (*androidNinjaGenerator) kernelModuleActions(m *ModuleKernelObject, ctx blu
 	}
 }
 
+// packageActions implements generatorBackend.
+func (*androidNinjaGenerator) packageActions(m *ModulePackage, ctx blueprint.ModuleContext) {
+	// Android builds its own images, so packages are not produced.
+}
+
 // resourceActions implements generatorBackend.
 func (g *androidNinjaGenerator) resourceActions(m *ModuleResource, ctx blueprint.ModuleContext) {
 	installDeps := g.install(m, ctx)
@@ -80,6 +80,14 @@ func (g *AndroidPlatform) GetToolchain(tgt toolchain.TgtType) toolchain.Toolchai
 	return g.toolchains.GetToolchain(tgt)
 }
 
+func (g *AndroidPlatform) TargetVariants() []toolchain.TgtType {
+	return g.toolchains.TargetVariants()
+}
+
+func (g *AndroidPlatform) InstallVariantDir(toolchain.TgtType) string {
+	return ""
+}
+
 func NewAndroidPlatform(env *config.EnvironmentVariables, cfg *config.Properties) Platform {
 	p := AndroidPlatform{
 		env: env,
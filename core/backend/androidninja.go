@@ -50,6 +50,16 @@ func (g *AndroidNinjaPlatform) GetToolchain(tgt toolchain.TgtType) toolchain.Too
 	return g.toolchains.GetToolchain(tgt)
 }
 
+// TargetVariants implements Platform.
+func (g *AndroidNinjaPlatform) TargetVariants() []toolchain.TgtType {
+	return g.toolchains.TargetVariants()
+}
+
+// InstallVariantDir implements Platform.
+func (g *AndroidNinjaPlatform) InstallVariantDir(toolchain.TgtType) string {
+	return ""
+}
+
 // Init implements Platform.
 func (g *AndroidNinjaPlatform) Init(config *config.Properties) {
 	g.toolchains.Configure(config)
@@ -12,7 +12,7 @@ import (
 )
 
 // Backend platform singleton.
-// Abstracts platform specific (AOSP, Ninja) parameters and stores toolchains.
+// Abstracts platform specific (AOSP, Ninja, Bazel, CMake) parameters and stores toolchains.
 type Platform interface {
 	BuildDir() string
 	SourceDir() string
@@ -25,6 +25,8 @@ type Platform interface {
 	EscapeFlag(string) string
 	Init(*config.Properties)
 	GetToolchain(tgt toolchain.TgtType) toolchain.Toolchain
+	TargetVariants() []toolchain.TgtType
+	InstallVariantDir(tgt toolchain.TgtType) string
 }
 
 var platform Platform
@@ -47,6 +49,10 @@ func Setup(env *config.EnvironmentVariables, cfg *config.Properties) {
 		lock.Lock()
 		defer lock.Unlock()
 		if platform == nil {
+			if cfg.GetStringIfExists("target_variants") != "" && !cfg.GetBool("builder_ninja") {
+				utils.Die("target_variants is only supported by the Linux builder")
+			}
+
 			switch {
 			case cfg.GetBool("builder_ninja"):
 				platform = NewLinuxPlatform(env, cfg)
@@ -54,6 +60,10 @@ func Setup(env *config.EnvironmentVariables, cfg *config.Properties) {
 				platform = NewAndroidPlatform(env, cfg)
 			case cfg.GetBool("builder_android_ninja"):
 				platform = NewAndroidNinjaPlatform(env, cfg)
+			case cfg.GetBool("builder_bazel"):
+				platform = NewBazelPlatform(env, cfg)
+			case cfg.GetBool("builder_cmake"):
+				platform = NewCMakePlatform(env, cfg)
 			default:
 				utils.Die("Unknown builder backend")
 			}
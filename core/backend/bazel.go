@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"path/filepath"
+
+	"github.com/ARM-software/bob-build/core/config"
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/google/blueprint"
+)
+
+type BazelPlatform struct {
+	toolchains toolchain.ToolchainSet
+	env        *config.EnvironmentVariables
+}
+
+var _ Platform = (*BazelPlatform)(nil)
+
+func (g *BazelPlatform) BuildDir() string {
+	// The Bazel backend writes BUILD.bazel files, whose outputs are
+	// placed by Bazel. Like the androidbp backend, it never references
+	// an output directory.
+	return ""
+}
+
+func (g *BazelPlatform) SourceDir() string {
+	return g.env.SrcDir
+}
+
+func (g *BazelPlatform) BobScriptsDir() string {
+	srcToScripts, _ := filepath.Rel(g.SourceDir(), filepath.Join(g.env.BobDir, "scripts"))
+	return srcToScripts
+}
+
+func (g *BazelPlatform) SourceOutputDir(m blueprint.Module) string {
+	return ""
+}
+
+func (g *BazelPlatform) SharedLibsDir(toolchain.TgtType) string {
+	// Bazel writes the link command lines.
+	return ""
+}
+
+func (g *BazelPlatform) StaticLibOutputDir(tgt toolchain.TgtType) string {
+	return ""
+}
+
+func (g *BazelPlatform) BinaryOutputDir(toolchain.TgtType) string {
+	return ""
+}
+
+func (g *BazelPlatform) KernelModOutputDir() string {
+	return ""
+}
+
+func (g *BazelPlatform) EscapeFlag(s string) string {
+	// Bazel tokenizes `copts` and `linkopts` itself, so flags are
+	// passed through.
+	return s
+}
+
+func (g *BazelPlatform) Init(config *config.Properties) {
+	g.toolchains.Configure(config)
+}
+
+func (g *BazelPlatform) GetToolchain(tgt toolchain.TgtType) toolchain.Toolchain {
+	return g.toolchains.GetToolchain(tgt)
+}
+
+func (g *BazelPlatform) TargetVariants() []toolchain.TgtType {
+	return g.toolchains.TargetVariants()
+}
+
+func (g *BazelPlatform) InstallVariantDir(toolchain.TgtType) string {
+	return ""
+}
+
+func NewBazelPlatform(env *config.EnvironmentVariables, cfg *config.Properties) Platform {
+	p := BazelPlatform{
+		env: env,
+	}
+
+	p.Init(cfg)
+
+	return &p
+}
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"path/filepath"
+
+	"github.com/ARM-software/bob-build/core/config"
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/google/blueprint"
+)
+
+type CMakePlatform struct {
+	toolchains toolchain.ToolchainSet
+	env        *config.EnvironmentVariables
+}
+
+var _ Platform = (*CMakePlatform)(nil)
+
+// Paths are relative to the project directories rather than the
+// top-level ones, so that the exported project can be added to another
+// CMake project with add_subdirectory().
+func (g *CMakePlatform) BuildDir() string {
+	return "${PROJECT_BINARY_DIR}"
+}
+
+func (g *CMakePlatform) SourceDir() string {
+	return "${PROJECT_SOURCE_DIR}"
+}
+
+func (g *CMakePlatform) BobScriptsDir() string {
+	srcToScripts, _ := filepath.Rel(g.env.SrcDir, filepath.Join(g.env.BobDir, "scripts"))
+	return filepath.Join(g.SourceDir(), srcToScripts)
+}
+
+func (g *CMakePlatform) SourceOutputDir(m blueprint.Module) string {
+	return filepath.Join("${PROJECT_BINARY_DIR}", "gen", m.Name())
+}
+
+// CMake chooses where the libraries and executables of its targets are
+// written, and they are referred to by target name, so their output
+// directories are never used.
+func (g *CMakePlatform) SharedLibsDir(toolchain.TgtType) string {
+	return ""
+}
+
+func (g *CMakePlatform) StaticLibOutputDir(tgt toolchain.TgtType) string {
+	return ""
+}
+
+func (g *CMakePlatform) BinaryOutputDir(toolchain.TgtType) string {
+	return ""
+}
+
+func (g *CMakePlatform) KernelModOutputDir() string {
+	return ""
+}
+
+func (g *CMakePlatform) EscapeFlag(s string) string {
+	// Each flag is a separate argument of a CMake command, which is
+	// quoted by the writer if needed.
+	return s
+}
+
+func (g *CMakePlatform) Init(config *config.Properties) {
+	g.toolchains.Configure(config)
+}
+
+func (g *CMakePlatform) GetToolchain(tgt toolchain.TgtType) toolchain.Toolchain {
+	return g.toolchains.GetToolchain(tgt)
+}
+
+func (g *CMakePlatform) TargetVariants() []toolchain.TgtType {
+	return g.toolchains.TargetVariants()
+}
+
+func (g *CMakePlatform) InstallVariantDir(toolchain.TgtType) string {
+	return ""
+}
+
+func NewCMakePlatform(env *config.EnvironmentVariables, cfg *config.Properties) Platform {
+	p := CMakePlatform{
+		env: env,
+	}
+
+	p.Init(cfg)
+
+	return &p
+}
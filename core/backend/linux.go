@@ -59,6 +59,20 @@ func (g *LinuxPlatform) GetToolchain(tgt toolchain.TgtType) toolchain.Toolchain
 	return g.toolchains.GetToolchain(tgt)
 }
 
+func (g *LinuxPlatform) TargetVariants() []toolchain.TgtType {
+	return g.toolchains.TargetVariants()
+}
+
+// InstallVariantDir returns the subdirectory of its install path which a
+// module built for a target variant other than the primary one is installed
+// to, so that its variants do not install the same files.
+func (g *LinuxPlatform) InstallVariantDir(tgt toolchain.TgtType) string {
+	if tgt == toolchain.TgtTypeHost || tgt == toolchain.TgtTypeTarget || tgt == g.TargetVariants()[0] {
+		return ""
+	}
+	return string(tgt)
+}
+
 func NewLinuxPlatform(_ *config.EnvironmentVariables, cfg *config.Properties) Platform {
 	l := LinuxPlatform{}
 
@@ -0,0 +1,470 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/file"
+	"github.com/ARM-software/bob-build/internal/bzlwriter"
+	"github.com/ARM-software/bob-build/internal/utils"
+	"github.com/ARM-software/bob-build/internal/warnings"
+)
+
+// The Bazel backend exports the strict modules of a project to
+// BUILD.bazel files, one for each directory holding a build.bp, so that
+// the project can be migrated to Bazel. Legacy module types, which have
+// no Bazel equivalent, are reported through the WarningLogger.
+
+const (
+	bazelBuildFile = "BUILD.bazel"
+	bazelHeader    = "# Generated by Bob from build.bp. Do not edit.\n\n"
+	bazelCcDefs    = "@rules_cc//cc:defs.bzl"
+)
+
+var (
+	bazelTree = bzlwriter.TreeFactory()
+
+	// Packages of the exported tree, and the package each module is
+	// declared in. Filled in by collectBazelPackagesMutator.
+	bazelPackagesMap       = map[string]bool{}
+	bazelModulePackagesMap = map[string]string{}
+)
+
+type bazelGenerator struct {
+}
+
+/* Compile time checks for interfaces that must be implemented by bazelGenerator */
+var _ generatorBackend = (*bazelGenerator)(nil)
+
+// Returns the Bazel package of a directory relative to the source
+// directory. The root package is "".
+func bazelPackage(dir string) string {
+	dir = filepath.Clean(dir)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func collectBazelPackagesMutator(ctx blueprint.BottomUpMutatorContext) {
+	pkg := bazelPackage(ctx.ModuleDir())
+	bazelPackagesMap[pkg] = true
+	bazelModulePackagesMap[ctx.ModuleName()] = pkg
+}
+
+// Returns the label of a module, relative to the package `pkg`.
+func bazelModuleLabel(pkg, name string) string {
+	owner := bazelModulePackagesMap[name]
+	if owner == pkg {
+		return ":" + name
+	}
+	return "//" + owner + ":" + name
+}
+
+// Returns the label of a file relative to the source directory, as seen
+// from the package `pkg`. A file belongs to the closest package above
+// it. Files of other packages are exported by their package.
+func bazelFileLabel(pkg, path string) string {
+	owner := ""
+	for dir := filepath.Dir(path); dir != "." && dir != "/" && dir != ".."; dir = filepath.Dir(dir) {
+		if bazelPackagesMap[dir] {
+			owner = dir
+			break
+		}
+	}
+
+	rel := path
+	if owner != "" {
+		rel = strings.TrimPrefix(path, owner+"/")
+	}
+	if owner == pkg {
+		return rel
+	}
+
+	bazelTree.File(owner).ExportFiles(rel)
+	return "//" + owner + ":" + rel
+}
+
+// Converts a list mixing paths relative to the source directory and
+// `:module` references into labels relative to the package `pkg`.
+func bazelLabels(pkg string, list []string) (labels []string) {
+	for _, s := range list {
+		if strings.HasPrefix(s, ":") {
+			labels = append(labels, bazelModuleLabel(pkg, s[1:]))
+		} else {
+			labels = append(labels, bazelFileLabel(pkg, s))
+		}
+	}
+	return
+}
+
+// Returns the labels of the sources of a module: the files its globs
+// matched when Bob ran, then the modules providing more sources.
+func bazelSources(pkg string, files file.Paths, targets []string) (srcs []string) {
+	files.ForEach(
+		func(fp file.Path) bool {
+			srcs = append(srcs, bazelFileLabel(pkg, fp.UnScopedPath()))
+			return true
+		})
+	for _, t := range targets {
+		srcs = append(srcs, bazelModuleLabel(pkg, t))
+	}
+	return
+}
+
+func (g *bazelGenerator) newRule(ctx blueprint.ModuleContext, kind string) (string, bzlwriter.Rule) {
+	pkg := bazelPackage(ctx.ModuleDir())
+	f := bazelTree.File(pkg)
+
+	if strings.HasPrefix(kind, "cc_") {
+		f.Load(bazelCcDefs, kind)
+	}
+
+	rule, err := f.NewRule(kind, ctx.ModuleName())
+	if err != nil {
+		utils.Die("%v", err.Error())
+	}
+	return pkg, rule
+}
+
+// Reports a module type which has no Bazel equivalent.
+func (g *bazelGenerator) unsupportedActions(m blueprint.Module, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		warnModule(ctx, warnings.BazelUnsupportedModule, ctx.ModuleType())
+	}
+}
+
+// aliasActions implements generatorBackend.
+func (g *bazelGenerator) aliasActions(m *ModuleAlias, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// binaryActions implements generatorBackend.
+func (g *bazelGenerator) binaryActions(m *ModuleBinary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// generateSourceActions implements generatorBackend.
+func (g *bazelGenerator) generateSourceActions(m *ModuleGenerateSource, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// gensrcsActions implements generatorBackend.
+func (g *bazelGenerator) gensrcsActions(m *ModuleGensrcs, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// transformSourceActions implements generatorBackend.
+func (g *bazelGenerator) transformSourceActions(m *ModuleTransformSource, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genSharedActions implements generatorBackend.
+func (g *bazelGenerator) genSharedActions(m *generateSharedLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genStaticActions implements generatorBackend.
+func (g *bazelGenerator) genStaticActions(m *generateStaticLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genBinaryActions implements generatorBackend.
+func (g *bazelGenerator) genBinaryActions(m *generateBinary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// kernelModuleActions implements generatorBackend.
+func (g *bazelGenerator) kernelModuleActions(m *ModuleKernelObject, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// packageActions implements generatorBackend.
+func (g *bazelGenerator) packageActions(m *ModulePackage, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// sharedActions implements generatorBackend.
+func (g *bazelGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// staticActions implements generatorBackend.
+func (g *bazelGenerator) staticActions(m *ModuleStaticLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// resourceActions implements generatorBackend.
+func (g *bazelGenerator) resourceActions(m *ModuleResource, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// importCCLibraryActions implements generatorBackend.
+func (g *bazelGenerator) importCCLibraryActions(m *ModuleImportCCLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// importCCBinaryActions implements generatorBackend.
+func (g *bazelGenerator) importCCBinaryActions(m *ModuleImportCCBinary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// Translate `bob_filegroup` to `filegroup`
+func (g *bazelGenerator) filegroupActions(m *ModuleFilegroup, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	pkg, rule := g.newRule(ctx, "filegroup")
+	rule.AddStringList("srcs", bazelSources(pkg, m.Properties.GetDirectFiles(), m.Properties.GetTargets()))
+}
+
+// Translate `bob_glob` to a `filegroup` whose sources are found by
+// glob(). Globs have no build actions, so this is called by the singleton.
+func bazelGlobActions(m *ModuleGlob, dir string) {
+	pkg := bazelPackage(dir)
+	rule, err := bazelTree.File(pkg).NewRule("filegroup", m.Name())
+	if err != nil {
+		utils.Die("%v", err.Error())
+	}
+
+	include := []string{}
+	exclude := []string{}
+	for _, p := range m.Properties.Srcs {
+		rel, _ := filepath.Rel(dir, p)
+		include = append(include, rel)
+	}
+	for _, p := range m.Properties.Exclude {
+		rel, _ := filepath.Rel(dir, p)
+		exclude = append(exclude, rel)
+	}
+
+	// glob() cannot match files outside of its package, so list the
+	// files Bob found instead.
+	for _, p := range append(include, exclude...) {
+		if strings.HasPrefix(p, "../") {
+			rule.AddStringList("srcs", bazelSources(pkg, m.Properties.Files, nil))
+			return
+		}
+	}
+
+	rule.AddGlob("srcs", include, exclude, m.Properties.Allow_empty)
+}
+
+// Converts a `bob_genrule` command to the `cmd` of a Bazel `genrule`.
+// Returns the command and the labels of the tools it uses.
+func (g *bazelGenerator) genruleCmd(gc *ModuleStrictGenerateCommon, ctx blueprint.ModuleContext, pkg string) (string, []string) {
+	props := &gc.Properties.StrictGenerateProps
+
+	toolLabel := func(tool string) string {
+		if idx := strings.LastIndex(tool, ":"); idx > 0 {
+			tool = tool[:idx]
+		}
+		return bazelModuleLabel(pkg, tool)
+	}
+
+	tools := []string{}
+	for _, t := range props.Tools {
+		tools = append(tools, toolLabel(t))
+	}
+	tools = append(tools, bazelLabels(pkg, props.Tool_files)...)
+
+	deps := append(props.GetTargets(), utils.MixedListToBobTargets(props.Tool_files)...)
+
+	location := func(tag string) string {
+		switch {
+		case strings.HasPrefix(tag, ":"):
+			// Modules may have several outputs
+			return "$(locations " + bazelModuleLabel(pkg, tag[1:]) + ")"
+		case utils.Contains(props.Tools, tag):
+			return "$(location " + toolLabel(tag) + ")"
+		case utils.Contains(props.Tool_files, tag):
+			return "$(location " + bazelFileLabel(pkg, tag) + ")"
+		default:
+			return "$(location " + bazelFileLabel(pkg, filepath.Join(ctx.ModuleDir(), tag)) + ")"
+		}
+	}
+
+	cmd := utils.Expand(*props.Cmd, func(s string) string {
+		switch {
+		case s == "in":
+			return "$(SRCS)"
+		case s == "out":
+			return "$(OUTS)"
+		case s == "genDir" || s == "gen_dir":
+			return "$(RULEDIR)"
+		case s == "location":
+			// The first of `tools` or `tool_files`
+			if len(props.Tools) > 0 {
+				return location(props.Tools[0])
+			} else if len(props.Tool_files) > 0 {
+				return location(props.Tool_files[0])
+			}
+			return "$(location)"
+		case strings.HasPrefix(s, "location "):
+			return location(strings.TrimPrefix(s, "location "))
+		case strings.HasSuffix(s, "_out") && utils.Contains(deps, strings.TrimSuffix(s, "_out")):
+			// `${name_out}` are the outputs of a module in `srcs` or `tool_files`
+			return "$(locations " + bazelModuleLabel(pkg, strings.TrimSuffix(s, "_out")) + ")"
+		default:
+			return "$(" + s + ")"
+		}
+	})
+
+	return strings.TrimSpace(cmd), tools
+}
+
+// Translate `bob_genrule` to `genrule`
+func (g *bazelGenerator) genruleActions(m *ModuleGenrule, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	gc := &m.ModuleStrictGenerateCommon
+	pkg, rule := g.newRule(ctx, "genrule")
+	cmd, tools := g.genruleCmd(gc, ctx, pkg)
+
+	rule.AddStringList("srcs", bazelSources(pkg, gc.Properties.GetDirectFiles(), gc.Properties.GetTargets()))
+	rule.AddStringList("outs", m.Properties.Out)
+	rule.AddString("cmd", cmd)
+	rule.AddStringList("tools", tools)
+}
+
+// Adds the attributes shared by `cc_library`, `cc_binary` and `cc_test`.
+// Executables have no `hdrs`, so their headers are part of `srcs`.
+func (g *bazelGenerator) addCcProps(rule bzlwriter.Rule, m *ModuleStrictLibrary, ctx blueprint.ModuleContext,
+	pkg string, hdrsInSrcs bool) {
+
+	props := &m.Properties.StrictLibraryProps
+	srcs := bazelSources(pkg, props.GetDirectFiles(), props.GetTargets())
+	hdrs := bazelLabels(pkg, props.Hdrs)
+	if hdrsInSrcs {
+		srcs = append(srcs, hdrs...)
+		hdrs = nil
+	}
+
+	// `includes` are relative to the package
+	includes := []string{}
+	for _, inc := range m.Properties.Includes {
+		rel, _ := filepath.Rel(ctx.ModuleDir(), inc)
+		includes = append(includes, rel)
+	}
+
+	deps := []string{}
+	for _, d := range props.Deps {
+		deps = append(deps, bazelModuleLabel(pkg, d))
+	}
+
+	features := []string{}
+	if layeringCheckEnabled(ctx, m) {
+		features = append(features, "layering_check")
+	} else if props.Layering_check != nil {
+		features = append(features, "-layering_check")
+	}
+
+	rule.AddStringList("srcs", srcs)
+	rule.AddStringList("hdrs", hdrs)
+	rule.AddStringList("copts", props.Copts)
+	rule.AddStringList("defines", m.Properties.Defines)
+	rule.AddStringList("includes", includes)
+	rule.AddStringList("linkopts", props.Linkopts)
+	rule.AddStringList("local_defines", props.Local_defines)
+	rule.AddStringList("deps", deps)
+	rule.AddStringList("features", features)
+}
+
+// Translate strict `bob_library` to `cc_library`
+func (g *bazelGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	pkg, rule := g.newRule(ctx, "cc_library")
+	g.addCcProps(rule, m, ctx, pkg, false)
+	rule.AddOptionalBool("alwayslink", m.Properties.Alwayslink)
+	rule.AddOptionalBool("linkstatic", m.Properties.Linkstatic)
+}
+
+// Translate strict `bob_executable` to `cc_binary`
+func (g *bazelGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	pkg, rule := g.newRule(ctx, "cc_binary")
+	g.addCcProps(rule, &m.ModuleStrictLibrary, ctx, pkg, true)
+}
+
+// Bazel only supports a fixed set of test timeouts. Their durations
+// match the ones Bob uses for each test size.
+func bazelTimeout(seconds int) string {
+	for _, t := range []struct{ timeout, size string }{
+		{"short", "small"},
+		{"moderate", "medium"},
+		{"long", "large"},
+	} {
+		if seconds <= testSizeTimeouts[t.size] {
+			return t.timeout
+		}
+	}
+	return "eternal"
+}
+
+// Translate `bob_test` to `cc_test`
+func (g *bazelGenerator) executableTestActions(m *ModuleTest, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	props := &m.TestProperties.TestProps
+	pkg, rule := g.newRule(ctx, "cc_test")
+	g.addCcProps(rule, &m.ModuleStrictLibrary, ctx, pkg, true)
+
+	env := map[string]string{}
+	for _, e := range props.Env {
+		kv := strings.SplitN(e, "=", 2)
+		env[kv[0]] = kv[1]
+	}
+
+	rule.AddStringList("args", props.Args)
+	rule.AddStringDict("env", env)
+	rule.AddStringList("data", bazelLabels(pkg, props.Data))
+	rule.AddOptionalString("size", props.Size)
+	if props.Timeout != nil {
+		rule.AddString("timeout", bazelTimeout(*props.Timeout))
+	}
+	if props.Shard_count != nil {
+		rule.AddInt("shard_count", *props.Shard_count)
+	}
+	rule.AddOptionalBool("flaky", props.Flaky)
+}
+
+type bazelSingleton struct {
+}
+
+func bazelSingletonFactory() blueprint.Singleton {
+	return &bazelSingleton{}
+}
+
+func (s *bazelSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		if gl, ok := m.(*ModuleGlob); ok {
+			bazelGlobActions(gl, ctx.ModuleDir(m))
+		}
+	})
+
+	for pkg := range bazelPackagesMap {
+		// Bob has no visibility rules
+		bazelTree.File(pkg).Package().AddStringList("default_visibility", []string{"//visibility:public"})
+	}
+
+	for _, pkg := range bazelTree.Packages() {
+		sb := &strings.Builder{}
+		sb.WriteString(bazelHeader)
+		bazelTree.File(pkg).Render(sb)
+
+		writeExportedFile(ctx, getPathInSourceDir(pkg, bazelBuildFile), bazelHeader, sb)
+	}
+}
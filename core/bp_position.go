@@ -0,0 +1,80 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/blueprint/parser"
+)
+
+// The .bp files parsed to locate warnings, which are only read when a
+// warning is raised in them.
+var (
+	bpFiles     = map[string]*parser.File{}
+	bpFilesLock sync.Mutex
+)
+
+func parseBpFile(bpFile string) *parser.File {
+	bpFilesLock.Lock()
+	defer bpFilesLock.Unlock()
+
+	if f, ok := bpFiles[bpFile]; ok {
+		return f
+	}
+
+	path := bpFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(getSourceDir(), path)
+	}
+
+	var file *parser.File
+	if r, err := os.Open(path); err == nil {
+		f, errs := parser.Parse(bpFile, r, parser.NewScope(nil))
+		r.Close()
+		if len(errs) == 0 {
+			file = f
+		}
+	}
+
+	// Files which cannot be read are recorded too, so they are only tried once.
+	bpFiles[bpFile] = file
+	return file
+}
+
+// bpPosition returns the line and column of a module's property in a .bp
+// file, or of the module itself when the property is empty or not set
+// directly in the module. Zeros are returned when the module is not found.
+func bpPosition(bpFile string, bpModule string, property string) (int, int) {
+	file := parseBpFile(bpFile)
+	if file == nil {
+		return 0, 0
+	}
+
+	for _, def := range file.Defs {
+		m, ok := def.(*parser.Module)
+		if !ok || bpModuleName(m) != bpModule {
+			continue
+		}
+
+		for _, prop := range m.Properties {
+			if property != "" && prop.Name == property {
+				return prop.NamePos.Line, prop.NamePos.Column
+			}
+		}
+		return m.TypePos.Line, m.TypePos.Column
+	}
+
+	return 0, 0
+}
+
+func bpModuleName(m *parser.Module) string {
+	for _, prop := range m.Properties {
+		if prop.Name == "name" {
+			if s, ok := prop.Value.(*parser.String); ok {
+				return s.Value
+			}
+		}
+	}
+	return ""
+}
@@ -15,6 +15,7 @@ type Build struct {
 	BuildProps
 	Target TargetSpecific
 	Host   TargetSpecific
+	VariantSpecific
 	SplittableProps
 }
 
@@ -23,6 +24,8 @@ func (b *Build) getTargetSpecific(tgt toolchain.TgtType) *TargetSpecific {
 		return &b.Host
 	} else if tgt == toolchain.TgtTypeTarget {
 		return &b.Target
+	} else if variant := b.VariantSpecific.get(tgt); variant != nil {
+		return variant
 	} else {
 		utils.Die("Unsupported target type: %s", tgt)
 	}
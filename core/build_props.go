@@ -121,6 +121,9 @@ type BuildProps struct {
 	StripProps
 	AndroidPGOProps
 	AndroidMTEProps
+	SanitizeProps
+	CoverageProps
+	PackageConfigProps
 
 	Hwasan_enabled *bool
 
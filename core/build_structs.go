@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/proptools"
@@ -55,6 +56,132 @@ func (t *TargetSpecific) getTargetSpecificProps() interface{} {
 	return t.BlueprintEmbed
 }
 
+// VariantSpecific holds a block of properties for each target variant
+// listed in `target_variants`, e.g. `arm32: {}`. The block of a variant is
+// applied after the `target: {}` block, which applies to every target
+// variant.
+type VariantSpecific struct {
+	// 'BlueprintEmbed' is a special case in Blueprint which makes it interpret
+	// a runtime-generated type as being embedded in its parent struct.
+	BlueprintEmbed interface{}
+}
+
+var variantStructTypeCache = struct {
+	sync.Mutex
+	types map[string]reflect.Type
+}{
+	types: make(map[string]reflect.Type),
+}
+
+// variantBlocks returns the target variants which have their own block of
+// properties. The `target` variant uses the `target: {}` block.
+func variantBlocks() (variants []toolchain.TgtType) {
+	for _, variant := range backend.Get().TargetVariants() {
+		if variant != toolchain.TgtTypeTarget {
+			variants = append(variants, variant)
+		}
+	}
+	return
+}
+
+// specificTargets returns the variants with a block of properties in each
+// module supporting them, starting with `host` and `target`.
+func specificTargets() []toolchain.TgtType {
+	return append([]toolchain.TgtType{toolchain.TgtTypeHost, toolchain.TgtTypeTarget}, variantBlocks()...)
+}
+
+// variantStructType generates a struct with a TargetSpecific field named
+// after each variant, e.g.
+//
+//	type BlueprintEmbedType struct {
+//	        Arm32 TargetSpecific
+//	        X86_64 TargetSpecific
+//	}
+func variantStructType(variants []toolchain.TgtType) reflect.Type {
+	key := strings.Join(tgtToString(variants), " ")
+
+	variantStructTypeCache.Lock()
+	defer variantStructTypeCache.Unlock()
+	if cached, ok := variantStructTypeCache.types[key]; ok {
+		return cached
+	}
+
+	fields := make([]reflect.StructField, len(variants))
+	for i, variant := range variants {
+		fields[i] = reflect.StructField{
+			Name: featurePropertyName(string(variant)),
+			Type: reflect.TypeOf(TargetSpecific{}),
+		}
+	}
+
+	cached := reflect.StructOf(fields)
+	variantStructTypeCache.types[key] = cached
+	return cached
+}
+
+// propertyNames returns the names of the properties in a struct, including
+// those of embedded structs. Blocks generated at runtime are not included.
+func propertyNames(t reflect.Type) (names []string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			names = append(names, propertyNames(field.Type)...)
+		} else if field.PkgPath == "" && field.Name != "BlueprintEmbed" {
+			names = append(names, proptools.PropertyNameForField(field.Name))
+		}
+	}
+	return
+}
+
+// checkVariantNames makes sure that the block of each target variant does
+// not have the name of a module property, e.g. `srcs`, `tags` or `host`.
+func checkVariantNames(cfg *BobConfig) {
+	variants := variantBlocks()
+	if len(variants) == 0 {
+		return
+	}
+
+	moduleTypes := map[string]string{} // Keyed by property
+	RegisterModuleTypes(func(name string, mf FactoryWithConfig) {
+		_, props := mf(cfg)
+		for _, p := range props {
+			for _, property := range propertyNames(reflect.TypeOf(p).Elem()) {
+				if _, ok := moduleTypes[property]; !ok {
+					moduleTypes[property] = name
+				}
+			}
+		}
+	})
+
+	for _, variant := range variants {
+		if moduleType, ok := moduleTypes[string(variant)]; ok {
+			configErrorf("target variant '%s' has the same name as a property of %s", variant, moduleType)
+		}
+	}
+}
+
+// init initializes the block of each variant, which can contain the same
+// properties as `target: {}`.
+func (v *VariantSpecific) init(properties *config.Properties, list ...interface{}) {
+	instance := reflect.New(variantStructType(variantBlocks())).Elem()
+	for i := 0; i < instance.NumField(); i++ {
+		instance.Field(i).Addr().Interface().(*TargetSpecific).init(properties, list...)
+	}
+	v.BlueprintEmbed = instance.Addr().Interface()
+}
+
+// get returns the block of a variant, or nil if it has none.
+func (v *VariantSpecific) get(tgt toolchain.TgtType) *TargetSpecific {
+	if v.BlueprintEmbed == nil {
+		return nil
+	}
+	field := reflect.ValueOf(v.BlueprintEmbed).Elem().FieldByName(featurePropertyName(string(tgt)))
+	if !field.IsValid() {
+		return nil
+	}
+	return field.Addr().Interface().(*TargetSpecific)
+}
+
 // A type implementing dependentInterface can be depended upon by other modules.
 // TODO: Delete this interface and move over all usage to File providers.
 // All instances of `outputs` should eventually be replaced. For now they
@@ -83,6 +210,7 @@ type generatorBackend interface {
 	genStaticActions(*generateStaticLibrary, blueprint.ModuleContext)
 	genBinaryActions(*generateBinary, blueprint.ModuleContext)
 	kernelModuleActions(*ModuleKernelObject, blueprint.ModuleContext)
+	packageActions(*ModulePackage, blueprint.ModuleContext)
 	sharedActions(*ModuleSharedLibrary, blueprint.ModuleContext)
 	staticActions(*ModuleStaticLibrary, blueprint.ModuleContext)
 	resourceActions(*ModuleResource, blueprint.ModuleContext)
@@ -117,6 +245,14 @@ type AndroidPGOProps struct {
 	}
 }
 
+// CoverageProps defines properties used to support code coverage. They
+// match the `native_coverage` property of Soong.
+type CoverageProps struct {
+	// Whether the module is instrumented when coverage is enabled in the
+	// configuration. Defaults to true.
+	Coverage *bool
+}
+
 // AndroidMTEProps defines properties used to enable the Arm Memory Tagging Extension
 type AndroidMTEProps struct {
 	Mte struct {
@@ -268,7 +404,7 @@ func stripEmptyComponentsMutator(ctx blueprint.BottomUpMutatorContext) {
 	strippableProps := f.FeaturableProperties()
 
 	if t, ok := ctx.Module().(targetSpecificLibrary); ok {
-		for _, tgt := range []toolchain.TgtType{toolchain.TgtTypeHost, toolchain.TgtTypeTarget} {
+		for _, tgt := range specificTargets() {
 			tgtSpecific := t.getTargetSpecific(tgt)
 			tgtSpecificData := tgtSpecific.getTargetSpecificProps()
 			strippableProps = append(strippableProps, tgtSpecificData)
@@ -300,9 +436,6 @@ const splitterMutatorName string = "bob_splitter"
 func parseAndAddVariationDeps(ctx blueprint.BottomUpMutatorContext,
 	tag blueprint.DependencyTag, deps ...string) {
 
-	hostVariation := []blueprint.Variation{{Mutator: splitterMutatorName, Variation: string(toolchain.TgtTypeHost)}}
-	targetVariation := []blueprint.Variation{{Mutator: splitterMutatorName, Variation: string(toolchain.TgtTypeTarget)}}
-
 	for _, dep := range deps {
 		var variations []blueprint.Variation
 
@@ -310,13 +443,12 @@ func parseAndAddVariationDeps(ctx blueprint.BottomUpMutatorContext,
 		if idx > 0 {
 			variationNames := strings.Split(dep[idx+1:], ",")
 			for _, vn := range variationNames {
-				if vn == "host" {
-					variations = append(variations, hostVariation...)
-				} else if vn == "target" {
-					variations = append(variations, targetVariation...)
-				} else {
+				tgt := toolchain.TgtType(vn)
+				if !isVariant(tgt) {
 					utils.Die("Invalid variation: %s in module name %s", vn, dep)
 				}
+				variations = append(variations,
+					blueprint.Variation{Mutator: splitterMutatorName, Variation: string(targetVariant(tgt))})
 			}
 
 			dep = dep[0:idx]
@@ -439,22 +571,31 @@ func targetMutator(ctx blueprint.TopDownMutatorContext) {
 
 		tgt := t.getTarget()
 
-		if tgt != toolchain.TgtTypeHost && tgt != toolchain.TgtTypeTarget {
+		if tgt == toolchain.TgtTypeUnknown {
 			// This is fine if target is neither host or target,
 			// it can happen if the target is the default
 			return
 		}
 
+		// Target variants get the `target: {}` block, followed by
+		// their own one
+		tgts := []toolchain.TgtType{tgt}
+		if tgt.IsTarget() && tgt != toolchain.TgtTypeTarget {
+			tgts = []toolchain.TgtType{toolchain.TgtTypeTarget, tgt}
+		}
+
 		dst := t.targetableProperties()
-		src := t.getTargetSpecific(tgt).getTargetSpecificProps()
-
-		// Copy the target-specific variables to the core set
-		err := AppendMatchingProperties(dst, src)
-		if err != nil {
-			if propertyErr, ok := err.(*proptools.ExtendPropertyError); ok {
-				ctx.PropertyErrorf(propertyErr.Property, "%s", propertyErr.Err.Error())
-			} else {
-				panic(err)
+		for _, tgt := range tgts {
+			src := t.getTargetSpecific(tgt).getTargetSpecificProps()
+
+			// Copy the target-specific variables to the core set
+			err := AppendMatchingProperties(dst, src)
+			if err != nil {
+				if propertyErr, ok := err.(*proptools.ExtendPropertyError); ok {
+					ctx.PropertyErrorf(propertyErr.Property, "%s", propertyErr.Err.Error())
+				} else {
+					panic(err)
+				}
 			}
 		}
 	}
@@ -616,7 +757,7 @@ func checkDisabledMutator(ctx blueprint.BottomUpMutatorContext) {
 	// disable current module if dependency is disabled, or panic if it's required
 	if len(disabledDeps) > 0 {
 		if isRequired(ep) {
-			utils.Die("Module %s is required but depends on disabled modules %s", module.Name(), strings.Join(disabledDeps, ", "))
+			moduleErrorf(ctx, "is required but depends on disabled modules %s", strings.Join(disabledDeps, ", "))
 		} else {
 			ep.getEnableableProps().Enabled = proptools.BoolPtr(false)
 			return
@@ -655,6 +796,7 @@ func RegisterModuleTypes(register func(string, FactoryWithConfig)) {
 	register("bob_kernel_module", kernelModuleFactory)
 	register("bob_resource", resourceFactory)
 	register("bob_install_group", installGroupFactory)
+	register("bob_package", packageFactory)
 
 	register("bob_toolchain", ModuleToolchainFactory)
 	register("bob_test", executableTestFactory)
@@ -0,0 +1,607 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/backend"
+	"github.com/ARM-software/bob-build/core/file"
+	"github.com/ARM-software/bob-build/core/flag"
+	"github.com/ARM-software/bob-build/internal/cmakewriter"
+	"github.com/ARM-software/bob-build/internal/utils"
+	"github.com/ARM-software/bob-build/internal/warnings"
+)
+
+// The CMake backend exports a project to CMakeLists.txt files, one for
+// each directory holding a build.bp, so that it can be opened in IDEs
+// and consumed by other CMake projects. The sources, flags and commands
+// of each module are the ones the Linux backend would use, with paths
+// relative to the CMake project directories. Module types which have no
+// CMake equivalent are reported through the WarningLogger.
+
+const (
+	cmakeListsFile      = "CMakeLists.txt"
+	cmakeHeader         = "# Generated by Bob from build.bp. Do not edit.\n\n"
+	cmakeMinimumVersion = "3.24"
+)
+
+var cmakeTree = cmakewriter.TreeFactory()
+
+type cmakeGenerator struct {
+}
+
+/* Compile time checks for interfaces that must be implemented by cmakeGenerator */
+var _ generatorBackend = (*cmakeGenerator)(nil)
+
+// Returns the directory of a CMakeLists.txt relative to the source
+// directory. The root directory is "".
+func cmakeDir(dir string) string {
+	return bazelPackage(dir)
+}
+
+// Returns whether a module is exported as a CMake target, which other
+// targets can depend on. Each target is written from a single variant,
+// but is also used by the dependents of the other variants, such as the
+// host tools of generators.
+func cmakeHasTarget(m blueprint.Module) bool {
+	if e, ok := m.(externableLibrary); ok && e.isExternal() {
+		return false
+	}
+	if e, ok := m.(enableable); ok && !isEnabled(e) {
+		return false
+	}
+
+	switch m.(type) {
+	case *ModuleAlias, *ModuleBinary, *ModuleSharedLibrary, *ModuleStaticLibrary,
+		*ModuleStrictLibrary, *ModuleStrictBinary, *ModuleTest,
+		*ModuleGenrule, *ModuleGensrcs:
+		return true
+	}
+	return false
+}
+
+// Returns the direct dependencies of a module which are CMake targets,
+// sorted.
+func cmakeTargetDeps(ctx blueprint.ModuleContext) (deps []string) {
+	ctx.VisitDirectDepsIf(
+		func(m blueprint.Module) bool { return cmakeHasTarget(m) },
+		func(m blueprint.Module) {
+			deps = utils.AppendIfUnique(deps, m.Name())
+		})
+	sort.Strings(deps)
+	return
+}
+
+// Returns the paths of source files, as seen by CMake.
+func cmakePaths(files file.Paths) (paths []string) {
+	files.ForEachIf(
+		func(fp file.Path) bool { return fp.IsNotType(file.TypeToc) },
+		func(fp file.Path) bool {
+			paths = append(paths, fp.BuildPath())
+			return true
+		})
+	return
+}
+
+func (g *cmakeGenerator) newBlock(ctx blueprint.ModuleContext) cmakewriter.Block {
+	b, err := cmakeTree.File(cmakeDir(ctx.ModuleDir())).NewBlock(ctx.ModuleName())
+	if err != nil {
+		utils.Die("%v", err.Error())
+	}
+	return b
+}
+
+// Reports a module type which has no CMake equivalent.
+func (g *cmakeGenerator) unsupportedActions(m blueprint.Module, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		warnModule(ctx, warnings.CMakeUnsupportedModule, ctx.ModuleType())
+	}
+}
+
+// Adds a scoped argument to a command, listing its values, if there are
+// any.
+type cmakeScopedArgs struct {
+	private, iface []string
+}
+
+func (s *cmakeScopedArgs) add(cmd func() cmakewriter.Command) {
+	if len(s.private) == 0 && len(s.iface) == 0 {
+		return
+	}
+	c := cmd()
+	if len(s.private) > 0 {
+		c.AddKeyword("PRIVATE", s.private...)
+	}
+	if len(s.iface) > 0 {
+		c.AddKeyword("INTERFACE", s.iface...)
+	}
+}
+
+// Adds the include directories, definitions and options a module is
+// compiled with, as computed for the Linux backend. The flags a module
+// exports are added to its interface, for the targets linking it.
+func cmakeCompileFlags(b cmakewriter.Block, name string, private, iface flag.Flags) {
+	var includes, systemIncludes, defines, options cmakeScopedArgs
+
+	classify := func(f flag.Flag, scope func(*cmakeScopedArgs) *[]string) {
+		s := f.ToString()
+		switch {
+		case f.MatchesType(flag.TypeInclude):
+			if f.MatchesType(flag.TypeIncludeSystem) {
+				*scope(&systemIncludes) = utils.AppendIfUnique(*scope(&systemIncludes), strings.TrimPrefix(s, "-isystem "))
+			} else {
+				*scope(&includes) = utils.AppendIfUnique(*scope(&includes), strings.TrimPrefix(s, "-I"))
+			}
+		case f.MatchesType(flag.TypeLinker | flag.TypeTransitiveLinker | flag.TypeLinkLibrary):
+			// Link flags are added with the link libraries
+		case (f.Type() & flag.TypeCompilable) == flag.TypeC:
+			*scope(&options) = append(*scope(&options), "$<$<COMPILE_LANGUAGE:C>:"+s+">")
+		case f.MatchesType(flag.TypeCC) && strings.HasPrefix(s, "-D"):
+			*scope(&defines) = utils.AppendIfUnique(*scope(&defines), strings.TrimPrefix(s, "-D"))
+		case f.MatchesType(flag.TypeCC):
+			*scope(&options) = append(*scope(&options), s)
+		case f.MatchesType(flag.TypeAsm):
+			*scope(&options) = append(*scope(&options), "$<$<COMPILE_LANGUAGE:ASM>:"+s+">")
+		case f.MatchesType(flag.TypeCpp):
+			*scope(&options) = append(*scope(&options), "$<$<COMPILE_LANGUAGE:CXX>:"+s+">")
+		}
+	}
+
+	private.ForEach(func(f flag.Flag) {
+		classify(f, func(a *cmakeScopedArgs) *[]string { return &a.private })
+	})
+	iface.Filtered(func(f flag.Flag) bool {
+		return f.MatchesType(flag.TypeExported | flag.TypeTransitive)
+	}).ForEach(func(f flag.Flag) {
+		classify(f, func(a *cmakeScopedArgs) *[]string { return &a.iface })
+	})
+
+	includes.add(func() cmakewriter.Command {
+		return b.NewCommand("target_include_directories", name)
+	})
+	systemIncludes.add(func() cmakewriter.Command {
+		return b.NewCommand("target_include_directories", name, "SYSTEM")
+	})
+	defines.add(func() cmakewriter.Command {
+		return b.NewCommand("target_compile_definitions", name)
+	})
+	options.add(func() cmakewriter.Command {
+		return b.NewCommand("target_compile_options", name)
+	})
+}
+
+// Adds the dependencies of a module on the generators of its sources and
+// headers. Unlike libraries, these are not implied by linking.
+func cmakeGeneratorDeps(b cmakewriter.Block, name string, ctx blueprint.ModuleContext) {
+	gens := []string{}
+	for _, dep := range cmakeTargetDeps(ctx) {
+		m, _ := ctx.GetDirectDep(dep)
+		if _, ok := getStrictGenerateCommon(m); ok {
+			gens = append(gens, dep)
+		}
+	}
+
+	if len(gens) > 0 {
+		b.NewCommand("add_dependencies", name).AddArgs(gens...)
+	}
+}
+
+// Installs the target of a module, or the files of a resource, into
+// the path of its install group, relative to the install prefix.
+func cmakeInstall(b cmakewriter.Block, props *InstallableProps, name string, files []string) {
+	path, ok := props.getInstallPath()
+	if !ok {
+		return
+	}
+
+	var c cmakewriter.Command
+	if files != nil {
+		c = b.NewCommand("install")
+		c.AddKeyword("FILES", files...)
+	} else {
+		c = b.NewCommand("install", "TARGETS", name)
+	}
+	c.AddKeyword("DESTINATION", path)
+}
+
+// Sets the properties of the outputs of a target, to match the file
+// names Bob uses.
+func cmakeOutputProperties(b cmakewriter.Block, name, outputName string, library bool, version string) {
+	props := []string{}
+	if library {
+		// Bob's library names already start with `lib`
+		props = append(props, "PREFIX", "")
+	}
+	if outputName != name {
+		props = append(props, "OUTPUT_NAME", outputName)
+	}
+	if version != "" {
+		props = append(props, "VERSION", version, "SOVERSION", strings.Split(version, ".")[0])
+	}
+
+	if len(props) > 0 {
+		b.NewCommand("set_target_properties", name).AddKeyword("PROPERTIES", props...)
+	}
+}
+
+// Translate a legacy library or binary. Static and shared libraries, and
+// those they reexport, are linked by name; external libraries through
+// the flags they export.
+func (g *cmakeGenerator) legacyActions(m *ModuleLibrary, ctx blueprint.ModuleContext, kind string) {
+	if m.isExternal() || !isExportedVariant(m) {
+		return
+	}
+
+	name := ctx.ModuleName()
+	b := g.newBlock(ctx)
+
+	head := []string{name}
+	if kind != "" {
+		head = append(head, kind)
+	}
+	if !isBuiltByDefault(m) {
+		head = append(head, "EXCLUDE_FROM_ALL")
+	}
+	if kind == "" {
+		b.NewCommand("add_executable", head...).AddArgs(cmakePaths(m.GetFiles(ctx))...)
+	} else {
+		b.NewCommand("add_library", head...).AddArgs(cmakePaths(m.GetFiles(ctx))...)
+	}
+
+	cmakeOutputProperties(b, name, m.outputName(), kind != "", m.Properties.Library_version)
+	cmakeCompileFlags(b, name, m.FlagsInTransitive(ctx), m.FlagsOut())
+
+	external := map[string][]string{}
+	ctx.VisitDirectDeps(func(dep blueprint.Module) {
+		if e, ok := dep.(externableLibrary); ok && e.isExternal() {
+			external[dep.Name()] = e.FlagsOut().Filtered(func(f flag.Flag) bool {
+				return f.MatchesType(flag.TypeLinkLibrary | flag.TypeLinker)
+			}).ToStringSlice()
+		}
+	})
+	libs := func(names []string, wrap string) (items []string) {
+		for _, lib := range names {
+			if flags, ok := external[lib]; ok {
+				items = append(items, flags...)
+			} else if wrap != "" {
+				items = append(items, "$<LINK_LIBRARY:"+wrap+","+lib+">")
+			} else {
+				items = append(items, lib)
+			}
+		}
+		return
+	}
+
+	var link cmakeScopedArgs
+	link.private = libs(m.Properties.Whole_static_libs, "WHOLE_ARCHIVE")
+	for _, lib := range libs(append(m.Properties.ResolvedStaticLibs, m.Properties.Shared_libs...), "") {
+		if utils.Contains(m.Properties.Reexport_libs, lib) {
+			link.iface = utils.AppendIfUnique(link.iface, lib)
+		} else {
+			link.private = utils.AppendIfUnique(link.private, lib)
+		}
+	}
+	link.private = append(link.private, m.FlagsIn().Filtered(func(f flag.Flag) bool {
+		return f.MatchesType(flag.TypeLinkLibrary)
+	}).ToStringSlice()...)
+
+	c := func() cmakewriter.Command { return b.NewCommand("target_link_libraries", name) }
+	if len(link.iface) > 0 {
+		// Reexported libraries are part of the interface, and also
+		// linked by the library itself.
+		c().AddKeyword("PUBLIC", link.iface...)
+		link.iface = nil
+	}
+	link.add(c)
+
+	// CMake sets the soname itself, from the VERSION property
+	ldflags := utils.Filter(func(s string) bool { return !strings.HasPrefix(s, "-Wl,-soname,") },
+		m.FlagsIn().Filtered(func(f flag.Flag) bool {
+			return f.MatchesType(flag.TypeLinker)
+		}).ToStringSlice())
+	if vs := m.getVersionScript(ctx); vs != nil {
+		tc := backend.Get().GetToolchain(m.getTarget())
+		ldflags = append(ldflags, tc.GetLinker().SetVersionScript(*vs))
+	}
+	if len(ldflags) > 0 {
+		b.NewCommand("target_link_options", name).AddKeyword("PRIVATE", ldflags...)
+	}
+
+	cmakeGeneratorDeps(b, name, ctx)
+	cmakeInstall(b, m.getInstallableProps(), name, nil)
+}
+
+// binaryActions implements generatorBackend.
+func (g *cmakeGenerator) binaryActions(m *ModuleBinary, ctx blueprint.ModuleContext) {
+	g.legacyActions(&m.ModuleLibrary, ctx, "")
+}
+
+// sharedActions implements generatorBackend.
+func (g *cmakeGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint.ModuleContext) {
+	g.legacyActions(&m.ModuleLibrary, ctx, "SHARED")
+}
+
+// staticActions implements generatorBackend.
+func (g *cmakeGenerator) staticActions(m *ModuleStaticLibrary, ctx blueprint.ModuleContext) {
+	g.legacyActions(&m.ModuleLibrary, ctx, "STATIC")
+}
+
+// Translate a strict library or executable. The type of strict libraries
+// is chosen by CMake's `BUILD_SHARED_LIBS`. Dependencies and `linkopts`
+// are propagated to the targets linking a library, as they are in Bazel.
+func (g *cmakeGenerator) strictActions(m *ModuleStrictLibrary, ctx blueprint.ModuleContext, executable bool) (string, cmakewriter.Block) {
+	name := ctx.ModuleName()
+	b := g.newBlock(ctx)
+
+	srcs := cmakePaths(m.GetFiles(ctx))
+	for _, h := range m.Properties.Hdrs {
+		srcs = append(srcs, getBackendPathInSourceDir(g, h))
+	}
+
+	head := []string{name}
+	if !isBuiltByDefault(m) {
+		head = append(head, "EXCLUDE_FROM_ALL")
+	}
+
+	scope := "PUBLIC"
+	if executable {
+		scope = "PRIVATE"
+		b.NewCommand("add_executable", head...).AddArgs(srcs...)
+	} else {
+		b.NewCommand("add_library", head...).AddArgs(srcs...)
+	}
+
+	cmakeOutputProperties(b, name, m.outputName(), !executable, "")
+	cmakeCompileFlags(b, name, m.FlagsInTransitive(ctx), m.FlagsOut())
+
+	link := append(append([]string{}, m.Properties.Deps...), m.Properties.Linkopts...)
+	if len(link) > 0 {
+		b.NewCommand("target_link_libraries", name).AddKeyword(scope, link...)
+	}
+
+	cmakeGeneratorDeps(b, name, ctx)
+	cmakeInstall(b, m.getInstallableProps(), name, nil)
+
+	return name, b
+}
+
+// strictLibraryActions implements generatorBackend.
+func (g *cmakeGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		g.strictActions(m, ctx, false)
+	}
+}
+
+// strictBinaryActions implements generatorBackend.
+func (g *cmakeGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		g.strictActions(&m.ModuleStrictLibrary, ctx, true)
+	}
+}
+
+// Translate `bob_test` to an executable run by CTest
+func (g *cmakeGenerator) executableTestActions(m *ModuleTest, ctx blueprint.ModuleContext) {
+	if !isExportedVariant(m) {
+		return
+	}
+
+	props := &m.TestProperties.TestProps
+	name, b := g.strictActions(&m.ModuleStrictBinary.ModuleStrictLibrary, ctx, true)
+
+	c := b.NewCommand("add_test")
+	c.AddKeyword("NAME", name)
+	c.AddKeyword("COMMAND", append([]string{name}, props.Args...)...)
+	if len(props.Data) > 0 {
+		// Data files are used from the source tree
+		c.AddKeyword("WORKING_DIRECTORY", backend.Get().SourceDir())
+	}
+
+	testProps := []string{}
+	if len(props.Env) > 0 {
+		testProps = append(testProps, "ENVIRONMENT", strings.Join(props.Env, ";"))
+	}
+	if timeout := props.timeout(); timeout > 0 {
+		testProps = append(testProps, "TIMEOUT", strconv.Itoa(timeout))
+	}
+	if len(testProps) > 0 {
+		b.NewCommand("set_tests_properties", name).AddKeyword("PROPERTIES", testProps...)
+	}
+}
+
+// Adds the custom commands of a `bob_genrule` or `bob_gensrcs`, using
+// the command the Linux backend would run, and a custom target building
+// their outputs.
+func (g *cmakeGenerator) generateStrictCommonActions(m *ModuleStrictGenerateCommon, ctx blueprint.ModuleContext, inouts []inout) {
+	name := ctx.ModuleName()
+	b := g.newBlock(ctx)
+
+	outputdir := backend.Get().SourceOutputDir(ctx.Module())
+	prefixInoutsWithOutputDir(inouts, outputdir)
+
+	cmd, args, implicits, _ := m.getArgs(ctx)
+
+	// Tools are referred to by target, and CMake places their outputs,
+	// so only keep the files of the project directories.
+	implicits = utils.Filter(func(s string) bool {
+		return strings.HasPrefix(s, backend.Get().SourceDir()) || strings.HasPrefix(s, backend.Get().BuildDir())
+	}, implicits)
+	if len(m.Properties.Tools) > 0 {
+		tool := m.Properties.Tools[0]
+		if idx := strings.LastIndex(tool, ":"); idx > 0 {
+			tool = tool[:idx]
+		}
+		args["host_bin"] = "$<TARGET_FILE:" + tool + ">"
+	}
+
+	// Targets in the dependencies add target level dependencies, which
+	// are needed for outputs of other directories.
+	targets := cmakeTargetDeps(ctx)
+
+	outs := []string{}
+	for _, io := range inouts {
+		command := utils.Expand(cmd, func(s string) string {
+			switch s {
+			case "in":
+				return strings.Join(io.in, " ")
+			case "_out_":
+				return strings.Join(io.out, " ")
+			case "depfile":
+				return io.depfile
+			}
+			return args[s]
+		})
+		// Undo Ninja's escaping, and keep semicolons in the command
+		// rather than splitting it into a list.
+		command = strings.Replace(command, "$$", "$", -1)
+		command = strings.Replace(command, ";", "$<SEMICOLON>", -1)
+
+		c := b.NewCommand("add_custom_command")
+		c.AddKeyword("OUTPUT", append(io.out, io.implicitOuts...)...)
+		c.AddKeyword("COMMAND", "sh", "-c", strings.TrimSpace(command))
+		depends := utils.NewStringSlice(io.in, io.implicitSrcs, implicits, targets)
+		if len(depends) > 0 {
+			c.AddKeyword("DEPENDS", utils.Unique(depends)...)
+		}
+		if io.depfile != "" {
+			c.AddKeyword("DEPFILE", io.depfile)
+		}
+		c.AddKeyword("VERBATIM")
+
+		outs = append(outs, io.out...)
+		outs = append(outs, io.implicitOuts...)
+	}
+
+	head := []string{name}
+	if isBuiltByDefault(m) {
+		head = append(head, "ALL")
+	}
+	b.NewCommand("add_custom_target", head...).AddKeyword("DEPENDS", outs...)
+}
+
+// genruleActions implements generatorBackend.
+func (g *cmakeGenerator) genruleActions(m *ModuleGenrule, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		g.generateStrictCommonActions(&m.ModuleStrictGenerateCommon, ctx, m.generateInouts(ctx))
+	}
+}
+
+// gensrcsActions implements generatorBackend.
+func (g *cmakeGenerator) gensrcsActions(m *ModuleGensrcs, ctx blueprint.ModuleContext) {
+	if isExportedVariant(m) {
+		g.generateStrictCommonActions(&m.ModuleStrictGenerateCommon, ctx, m.generateInouts(ctx))
+	}
+}
+
+// Translate `bob_alias` to a custom target depending on the targets it
+// aliases.
+func (g *cmakeGenerator) aliasActions(m *ModuleAlias, ctx blueprint.ModuleContext) {
+	b := g.newBlock(ctx)
+	b.NewCommand("add_custom_target", ctx.ModuleName())
+
+	deps := cmakeTargetDeps(ctx)
+	if len(deps) > 0 {
+		b.NewCommand("add_dependencies", ctx.ModuleName()).AddArgs(deps...)
+	}
+}
+
+// Resources have no build actions, they are only installed.
+func (g *cmakeGenerator) resourceActions(m *ModuleResource, ctx blueprint.ModuleContext) {
+	files := cmakePaths(m.OutFiles())
+	if _, ok := m.getInstallableProps().getInstallPath(); ok && len(files) > 0 {
+		cmakeInstall(g.newBlock(ctx), m.getInstallableProps(), ctx.ModuleName(), files)
+	}
+}
+
+// The files of filegroups are part of the sources of the modules using
+// them, so filegroups have no targets.
+func (g *cmakeGenerator) filegroupActions(m *ModuleFilegroup, ctx blueprint.ModuleContext) {
+}
+
+// generateSourceActions implements generatorBackend.
+func (g *cmakeGenerator) generateSourceActions(m *ModuleGenerateSource, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// transformSourceActions implements generatorBackend.
+func (g *cmakeGenerator) transformSourceActions(m *ModuleTransformSource, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genSharedActions implements generatorBackend.
+func (g *cmakeGenerator) genSharedActions(m *generateSharedLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genStaticActions implements generatorBackend.
+func (g *cmakeGenerator) genStaticActions(m *generateStaticLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// genBinaryActions implements generatorBackend.
+func (g *cmakeGenerator) genBinaryActions(m *generateBinary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// kernelModuleActions implements generatorBackend.
+func (g *cmakeGenerator) kernelModuleActions(m *ModuleKernelObject, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// packageActions implements generatorBackend.
+func (g *cmakeGenerator) packageActions(m *ModulePackage, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// importCCLibraryActions implements generatorBackend.
+func (g *cmakeGenerator) importCCLibraryActions(m *ModuleImportCCLibrary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+// importCCBinaryActions implements generatorBackend.
+func (g *cmakeGenerator) importCCBinaryActions(m *ModuleImportCCBinary, ctx blueprint.ModuleContext) {
+	g.unsupportedActions(m, ctx)
+}
+
+type cmakeSingleton struct {
+}
+
+func cmakeSingletonFactory() blueprint.Singleton {
+	return &cmakeSingleton{}
+}
+
+func (s *cmakeSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	cfg := getConfig(ctx)
+
+	// The top-level file declares the project, and adds the other
+	// directories.
+	root := cmakeTree.File("").Header()
+	root.NewCommand("cmake_minimum_required").AddKeyword("VERSION", cmakeMinimumVersion)
+	root.NewCommand("project", cfg.Properties.GetString("cmake_project_name")).AddKeyword("LANGUAGES", "C", "CXX", "ASM")
+
+	hasTests := false
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		if t, ok := m.(*ModuleTest); ok && isEnabled(t) {
+			hasTests = true
+		}
+	})
+	if hasTests {
+		root.NewCommand("enable_testing")
+	}
+
+	for _, dir := range cmakeTree.Dirs() {
+		if dir != "" {
+			root.NewCommand("add_subdirectory", dir)
+		}
+	}
+
+	for _, dir := range cmakeTree.Dirs() {
+		sb := &strings.Builder{}
+		sb.WriteString(cmakeHeader)
+		cmakeTree.File(dir).Render(sb)
+
+		writeExportedFile(ctx, getPathInSourceDir(dir, cmakeListsFile), cmakeHeader, sb)
+	}
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ARM-software/bob-build/core/config"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// An entry of a JSON compilation database, as described in
+// https://clang.llvm.org/docs/JSONCompilationDatabase.html
+type compileCommand struct {
+	Directory string `json:"directory"`
+	Command   string `json:"command"`
+	File      string `json:"file"`
+	Output    string `json:"output"`
+}
+
+var (
+	compileCommands     = []compileCommand{}
+	compileCommandsLock sync.Mutex
+)
+
+func compileCommandsEnabled() bool {
+	return config.GetEnvironmentVariables().CompileCommandsFile != ""
+}
+
+// Expand a string as Ninja would when running a command. Only the variables
+// which Bob uses in compiler invocations are known, any other variable is
+// left untouched.
+func expandNinjaString(s string) string {
+	vars := map[string]string{
+		"SrcDir":        getSourceDir(),
+		"BuildDir":      getBuildDir(),
+		"BobScriptsDir": getBobScriptsDir(),
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case '$', ' ', ':':
+			b.WriteByte(s[i])
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				b.WriteString("${")
+				continue
+			}
+			name := s[i+1 : i+end]
+			if value, ok := vars[name]; ok {
+				b.WriteString(value)
+			} else {
+				b.WriteString("${" + name + "}")
+			}
+			i += end
+		default:
+			b.WriteByte('$')
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// Records the command used to compile `source` into `output`. The
+// arguments are given as they are written to the Ninja file.
+//
+// The build wrapper is deliberately omitted, so that tools reading the
+// database see the real compiler.
+func addCompileCommand(source, output string, args ...[]string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		utils.Die("error getting the working directory: %v", err)
+	}
+
+	// Drop empty arguments, like unset flag variables, so that they do not
+	// leave repeated spaces in the command
+	for i := range args {
+		args[i] = utils.Trim(args[i])
+	}
+
+	cmd := compileCommand{
+		Directory: dir,
+		Command:   expandNinjaString(utils.Join(args...)),
+		File:      expandNinjaString(source),
+		Output:    expandNinjaString(output),
+	}
+
+	compileCommandsLock.Lock()
+	defer compileCommandsLock.Unlock()
+	compileCommands = append(compileCommands, cmd)
+}
+
+// Writes the compilation database to the specified file if the path is set.
+func CompileCommandsWriteToFile(file string) {
+	if file == "" {
+		return
+	}
+
+	// Modules are processed in parallel, so sort for a stable output.
+	sort.Slice(compileCommands, func(i, j int) bool {
+		return compileCommands[i].Output < compileCommands[j].Output
+	})
+
+	bytes, err := json.MarshalIndent(compileCommands, "", "  ")
+	if err != nil {
+		utils.Die("error converting to JSON from: '%v' error: %v", compileCommands, err)
+	}
+
+	err = ioutil.WriteFile(file, bytes, 0644)
+	if err != nil {
+		utils.Die("error writing to '%s' file: %v", file, err)
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/blueprint/bootstrap"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ARM-software/bob-build/core/config"
+)
+
+// setupCompileCommandsDirs sets the directories which Ninja variables
+// expand to, and returns a function restoring them.
+func setupCompileCommandsDirs() func() {
+	env := config.GetEnvironmentVariables()
+	srcDir, bobDir, buildDir := env.SrcDir, env.BobDir, bootstrap.BuildDir
+
+	env.SrcDir = "/src"
+	env.BobDir = "/bob"
+	bootstrap.BuildDir = "/build"
+
+	return func() {
+		env.SrcDir, env.BobDir, bootstrap.BuildDir = srcDir, bobDir, buildDir
+	}
+}
+
+func TestExpandNinjaString(t *testing.T) {
+	defer setupCompileCommandsDirs()()
+
+	assert.Equal(t, "gcc -c /src/a.c -o /build/target/objects/a.c.o",
+		expandNinjaString("gcc -c ${SrcDir}/a.c -o ${BuildDir}/target/objects/a.c.o"))
+	assert.Equal(t, "/bob/scripts/wrap.py", expandNinjaString("${BobScriptsDir}/wrap.py"))
+
+	// Escapes
+	assert.Equal(t, "-DVAR=$HOME a b:c", expandNinjaString("-DVAR=$$HOME a$ b$:c"))
+
+	// Unknown variables are left for the shell or the reader
+	assert.Equal(t, "${cflags} $cxxflags", expandNinjaString("${cflags} $cxxflags"))
+
+	// Unterminated variables and trailing `$`
+	assert.Equal(t, "${SrcDir", expandNinjaString("${SrcDir"))
+	assert.Equal(t, "a$", expandNinjaString("a$"))
+}
+
+func TestCompileCommandsWriteToFile(t *testing.T) {
+	defer setupCompileCommandsDirs()()
+	defer func() { compileCommands = []compileCommand{} }()
+
+	compileCommands = []compileCommand{}
+	addCompileCommand("${SrcDir}/b.c", "${BuildDir}/b.c.o",
+		[]string{"gcc", "-c", "-DB", ""}, []string{"${SrcDir}/b.c", "-o", "${BuildDir}/b.c.o"})
+	addCompileCommand("${SrcDir}/a.cpp", "${BuildDir}/a.cpp.o",
+		[]string{"g++", "-c", "", "-DA"}, []string{"${SrcDir}/a.cpp", "-o", "${BuildDir}/a.cpp.o"})
+
+	file := filepath.Join(t.TempDir(), "compile_commands.json")
+	CompileCommandsWriteToFile(file)
+
+	data, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+
+	commands := []compileCommand{}
+	assert.NoError(t, json.Unmarshal(data, &commands))
+
+	// Sorted by output
+	assert.Equal(t, 2, len(commands))
+	assert.Equal(t, "g++ -c -DA /src/a.cpp -o /build/a.cpp.o", commands[0].Command)
+	assert.Equal(t, "/src/a.cpp", commands[0].File)
+	assert.Equal(t, "/build/a.cpp.o", commands[0].Output)
+	assert.Equal(t, "gcc -c -DB /src/b.c -o /build/b.c.o", commands[1].Command)
+}
@@ -97,6 +97,23 @@ func (properties Properties) GetStringIfExists(name string) string {
 	return ""
 }
 
+// Rebase returns a copy of the properties where every property named
+// `<from>_<name>` replaces `<to>_<name>`. Properties which are not set for
+// `from` keep the value set for `to`.
+func (properties Properties) Rebase(from, to string) *Properties {
+	rebased := properties
+	rebased.Properties = make(map[string]interface{}, len(properties.Properties))
+	for key, value := range properties.Properties {
+		rebased.Properties[key] = value
+	}
+	for key, value := range properties.Properties {
+		if strings.HasPrefix(key, from+"_") {
+			rebased.Properties[to+"_"+strings.TrimPrefix(key, from+"_")] = value
+		}
+	}
+	return &rebased
+}
+
 func (properties Properties) StringMap() map[string]string {
 	return properties.stringMap
 }
@@ -225,6 +242,8 @@ func CreateMockConfig(overrides map[string]interface{}) *Properties {
 	properties.Properties["builder_ninja"] = false
 	properties.Properties["builder_android_bp"] = false
 	properties.Properties["builder_android_ninja"] = false
+	properties.Properties["builder_bazel"] = false
+	properties.Properties["builder_cmake"] = false
 	properties.Properties["as_binary"] = "as"
 
 	properties.Properties["target_toolchain_clang"] = false
@@ -239,6 +258,7 @@ func CreateMockConfig(overrides map[string]interface{}) *Properties {
 	properties.Properties["target_gnu_flags"] = ""
 	properties.Properties["target_nm_binary"] = "nm"
 	properties.Properties["target_ranlib_binary"] = "ranlib"
+	properties.Properties["target_gcov_binary"] = "gcov"
 	properties.Properties["target_64bit_only"] = false
 
 	properties.Properties["host_toolchain_clang"] = false
@@ -253,6 +273,7 @@ func CreateMockConfig(overrides map[string]interface{}) *Properties {
 	properties.Properties["host_gnu_flags"] = ""
 	properties.Properties["host_nm_binary"] = "nm"
 	properties.Properties["host_ranlib_binary"] = "ranlib"
+	properties.Properties["host_gcov_binary"] = "gcov"
 	properties.Properties["host_64bit_only"] = false
 	properties.Properties["custom_toolchain"] = false
 
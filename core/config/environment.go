@@ -7,14 +7,17 @@ import (
 
 // Stores all environment variables passed to Bob at runtime as a singleton.
 type EnvironmentVariables struct {
-	BobDir          string
-	SrcDir          string
-	ConfigOpts      string
-	ConfigFile      string
-	ConfigJSON      string
-	LogWarningsFile string
-	LogWarnings     string
-	BuildMetaFile   string
+	BobDir              string
+	SrcDir              string
+	ConfigOpts          string
+	ConfigFile          string
+	ConfigJSON          string
+	LogWarningsFile     string
+	LogWarningsFormat   string
+	LogWarnings         string
+	BuildMetaFile       string
+	CompileCommandsFile string
+	SourceDateEpoch     string
 }
 
 var env *EnvironmentVariables
@@ -29,14 +32,17 @@ func GetEnvironmentVariables() *EnvironmentVariables {
 		defer lock.Unlock()
 		if env == nil {
 			env = &EnvironmentVariables{
-				BobDir:          os.Getenv("BOB_DIR"),
-				SrcDir:          os.Getenv("SRCDIR"),
-				ConfigOpts:      os.Getenv("BOB_CONFIG_OPTS"),
-				ConfigFile:      os.Getenv("CONFIG_FILE"),
-				ConfigJSON:      os.Getenv("CONFIG_JSON"),
-				LogWarningsFile: os.Getenv("BOB_LOG_WARNINGS_FILE"),
-				LogWarnings:     os.Getenv("BOB_LOG_WARNINGS"),
-				BuildMetaFile:   os.Getenv("BOB_META_FILE"),
+				BobDir:              os.Getenv("BOB_DIR"),
+				SrcDir:              os.Getenv("SRCDIR"),
+				ConfigOpts:          os.Getenv("BOB_CONFIG_OPTS"),
+				ConfigFile:          os.Getenv("CONFIG_FILE"),
+				ConfigJSON:          os.Getenv("CONFIG_JSON"),
+				LogWarningsFile:     os.Getenv("BOB_LOG_WARNINGS_FILE"),
+				LogWarningsFormat:   os.Getenv("BOB_LOG_WARNINGS_FORMAT"),
+				LogWarnings:         os.Getenv("BOB_LOG_WARNINGS"),
+				BuildMetaFile:       os.Getenv("BOB_META_FILE"),
+				CompileCommandsFile: os.Getenv("BOB_COMPILE_COMMANDS_FILE"),
+				SourceDateEpoch:     os.Getenv("SOURCE_DATE_EPOCH"),
 			}
 		}
 	}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/toolchain"
+)
+
+// Modules implementing coverable are instrumented for code coverage when
+// it is enabled in the configuration.
+type coverable interface {
+	Compilable
+	getCoverageProps() *CoverageProps
+}
+
+func coverageEnabled(ctx configProvider) bool {
+	return getConfig(ctx).Properties.GetBool("coverage")
+}
+
+// Whether the module itself is instrumented. Modules are instrumented by
+// default, and opt out with `coverage: false`.
+func isCoverageInstrumented(ctx configProvider, m blueprint.Module) bool {
+	if !coverageEnabled(ctx) {
+		return false
+	}
+	c, ok := m.(coverable)
+	return ok && proptools.BoolDefault(c.getCoverageProps().Coverage, true)
+}
+
+// Returns the flags needed to compile a module with coverage
+// instrumentation, checking that the toolchain supports it.
+func coverageCompileFlags(ctx blueprint.ModuleContext, tc toolchain.Toolchain, m blueprint.Module) []string {
+	if !isCoverageInstrumented(ctx, m) {
+		return []string{}
+	}
+
+	cflags, _ := tc.GetCoverageFlags()
+	if len(cflags) == 0 {
+		moduleErrorf(ctx, "the toolchain does not support coverage, set `coverage: false` to build the module")
+	}
+	return cflags
+}
+
+// Returns the flags needed to link a module when coverage is enabled. These
+// are added even when the module itself is not instrumented, as it may link
+// libraries which are.
+func coverageLinkFlags(ctx blueprint.ModuleContext, tc toolchain.Toolchain) []string {
+	if !coverageEnabled(ctx) {
+		return []string{}
+	}
+
+	_, ldflags := tc.GetCoverageFlags()
+	return ldflags
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"sync"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/module"
 	"github.com/ARM-software/bob-build/core/tag"
 	"github.com/ARM-software/bob-build/core/toolchain"
@@ -26,7 +27,7 @@ type ModuleDefaults struct {
 }
 
 func (m *ModuleDefaults) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{toolchain.TgtTypeHost, toolchain.TgtTypeTarget}
+	return append([]toolchain.TgtType{toolchain.TgtTypeHost}, backend.Get().TargetVariants()...)
 }
 
 func (m *ModuleDefaults) disable() {
@@ -131,6 +132,7 @@ func defaultsFactory(config *BobConfig) (blueprint.Module, []interface{}) {
 	module.Properties.Features.Init(&config.Properties, CommonProps{}, BuildProps{}, KernelProps{}, SplittableProps{}, TagableProps{})
 	module.Properties.Host.init(&config.Properties, CommonProps{}, BuildProps{}, KernelProps{}, TagableProps{})
 	module.Properties.Target.init(&config.Properties, CommonProps{}, BuildProps{}, KernelProps{}, TagableProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, CommonProps{}, BuildProps{}, KernelProps{}, TagableProps{})
 
 	return module, []interface{}{&module.Properties, &module.SimpleName.Properties}
 }
@@ -186,7 +188,7 @@ func DefaultDepsStage1Mutator(ctx blueprint.BottomUpMutatorContext) {
 
 		// forbid the use of `srcs` and `exclude_srcs` in `bob_defaults` altogether
 		if len(srcs.Srcs) > 0 || len(srcs.Exclude_srcs) > 0 {
-			GetLogger().Warn(warnings.DefaultSrcsWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+			warnModule(ctx, warnings.DefaultSrcsWarning)
 		}
 	}
 
@@ -200,7 +202,7 @@ func DefaultDepsStage1Mutator(ctx blueprint.BottomUpMutatorContext) {
 	if gsc, ok := getGenerateCommon(ctx.Module()); ok {
 		if len(gsc.Properties.Flag_defaults) > 0 {
 			tgt := gsc.Properties.Target
-			if !(tgt == toolchain.TgtTypeHost || tgt == toolchain.TgtTypeTarget) {
+			if !isVariant(tgt) {
 				utils.Die("Module %s uses flag_defaults '%v' but has invalid target type '%s'",
 					ctx.ModuleName(), gsc.Properties.Flag_defaults, tgt)
 			}
@@ -12,6 +12,17 @@ type graphMutatorHandler struct {
 	graphs map[toolchain.TgtType]graph.Graph
 }
 
+// graph returns the graph of a variant. There is one graph per variant, so
+// that each target variant in a multilib build is sorted separately.
+func (handler *graphMutatorHandler) graph(tgt toolchain.TgtType) graph.Graph {
+	g, ok := handler.graphs[tgt]
+	if !ok {
+		g = graph.NewGraph("All")
+		handler.graphs[tgt] = g
+	}
+	return g
+}
+
 const (
 	maxInt = int(^uint(0) >> 1)
 	minInt = -maxInt - 1
@@ -32,7 +43,7 @@ func (handler *graphMutatorHandler) ResolveDependencySortMutator(ctx blueprint.B
 
 	if sp, ok := mainModule.(splittable); ok {
 		if sp.getTarget() != "" {
-			handler.graphs[sp.getTarget()].AddNode(mainModuleName)
+			handler.graph(sp.getTarget()).AddNode(mainModuleName)
 		}
 	}
 
@@ -44,11 +55,11 @@ func (handler *graphMutatorHandler) ResolveDependencySortMutator(ctx blueprint.B
 	}
 
 	// This mutator is run after host/target splitting, so TargetType should have been set.
-	if !(mainBuild.TargetType == toolchain.TgtTypeTarget || mainBuild.TargetType == toolchain.TgtTypeHost) {
+	if !(mainBuild.TargetType.IsTarget() || mainBuild.TargetType == toolchain.TgtTypeHost) {
 		utils.Die("Cannot process dependencies on module '%s' with target type '%s'", mainModuleName, mainBuild.TargetType)
 	}
 
-	g := handler.graphs[mainBuild.TargetType]
+	g := handler.graph(mainBuild.TargetType)
 
 	for _, lib := range mainBuild.Static_libs {
 		if _, err := g.AddEdgeToExistingNodes(mainModuleName, lib); err != nil {
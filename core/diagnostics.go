@@ -0,0 +1,64 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/config"
+	"github.com/ARM-software/bob-build/internal/diagnostics"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// Errors found in module definitions are collected, rather than stopping
+// at the first, and reported together once the mutators have run.
+var (
+	moduleDiagnostics     = diagnostics.New()
+	reportDiagnosticsOnce sync.Once
+)
+
+// propertyErrorf records an error about a property of the module. When the
+// property is not set in the module itself, such as when it comes from
+// defaults, the module's position is used.
+func propertyErrorf(ctx blueprint.BaseModuleContext, property string, format string, args ...interface{}) {
+	line, column := bpPosition(ctx.BlueprintsFile(), ctx.ModuleName(), property)
+	moduleDiagnostics.Add(diagnostics.Diagnostic{
+		File:    ctx.BlueprintsFile(),
+		Line:    line,
+		Column:  column,
+		Module:  ctx.ModuleName(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// moduleErrorf records an error about the module as a whole.
+func moduleErrorf(ctx blueprint.BaseModuleContext, format string, args ...interface{}) {
+	propertyErrorf(ctx, "", format, args...)
+}
+
+// configErrorf records an error in the configuration, such as an option
+// which clashes with module properties.
+func configErrorf(format string, args ...interface{}) {
+	moduleDiagnostics.Add(diagnostics.Diagnostic{
+		File:    config.GetEnvironmentVariables().ConfigFile,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// reportDiagnostics prints the recorded errors sorted by position, and
+// exits if there were any.
+func reportDiagnostics() {
+	if moduleDiagnostics.Len() == 0 {
+		return
+	}
+	count := moduleDiagnostics.Report(os.Stderr)
+	utils.Die("%d error(s) found in module definitions", count)
+}
+
+// reportDiagnosticsMutator runs after the checking mutators, so that their
+// errors are reported before any build actions are generated.
+func reportDiagnosticsMutator(ctx blueprint.BottomUpMutatorContext) {
+	reportDiagnosticsOnce.Do(reportDiagnostics)
+}
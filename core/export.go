@@ -0,0 +1,66 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/ARM-software/bob-build/internal/fileutils"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// Helpers shared by the backends which export the build.bp files to the
+// files of another build system, written into the source tree.
+
+// The exported build systems use a single toolchain, so each module is
+// only exported once, from its target variant if it has one. Disabled
+// modules are not exported.
+func isExportedVariant(m blueprint.Module) bool {
+	if e, ok := m.(enableable); ok && !isEnabled(e) {
+		return false
+	}
+
+	s, ok := m.(splittable)
+	if !ok {
+		return true
+	}
+
+	// Export the first target variant, if any
+	exported := toolchain.TgtTypeHost
+	for _, tgt := range s.supportedVariants() {
+		if tgt.IsTarget() {
+			exported = tgt
+			break
+		}
+	}
+	return s.getTarget() == exported
+}
+
+// Writes an exported file into the source tree. Files which do not
+// start with `header` were written by hand, and are never overwritten.
+func writeExportedFile(ctx blueprint.SingletonContext, path, header string, content *strings.Builder) {
+	if existing, err := ioutil.ReadFile(path); err == nil {
+		if !strings.HasPrefix(string(existing), header) {
+			utils.Die("%s was not generated by Bob, refusing to overwrite it", path)
+		}
+	} else if !os.IsNotExist(err) {
+		utils.Die("%v", err.Error())
+	}
+
+	err := fileutils.WriteIfChanged(path, content)
+	if err != nil {
+		utils.Die("%v", err.Error())
+	}
+
+	// As with the Android.bp backend, a dummy target is needed for the
+	// bob package context dependencies to be output.
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     dummyRule,
+			Outputs:  []string{path},
+			Optional: true,
+		})
+}
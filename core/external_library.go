@@ -1,6 +1,7 @@
 package core
 
 import (
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/flag"
 	"github.com/ARM-software/bob-build/core/module"
 	"github.com/ARM-software/bob-build/core/toolchain"
@@ -15,6 +16,7 @@ type ExternalLibProps struct {
 	Ldlibs         []string
 	Target         TargetSpecific
 	Host           TargetSpecific
+	VariantSpecific
 	SplittableProps
 	TargetType toolchain.TgtType `blueprint:"mutated"`
 }
@@ -71,7 +73,7 @@ func (m *ModuleExternalLibrary) supportedVariants() (tgts []toolchain.TgtType) {
 		tgts = append(tgts, toolchain.TgtTypeHost)
 	}
 	if m.Properties.isTargetSupported() {
-		tgts = append(tgts, toolchain.TgtTypeTarget)
+		tgts = append(tgts, backend.Get().TargetVariants()...)
 	}
 	return
 }
@@ -91,6 +93,8 @@ func (l ExternalLibProps) getTargetSpecific(tgt toolchain.TgtType) *TargetSpecif
 		return &l.Host
 	} else if tgt == toolchain.TgtTypeTarget {
 		return &l.Target
+	} else if variant := l.VariantSpecific.get(tgt); variant != nil {
+		return variant
 	} else {
 		utils.Die("Unsupported target type: %s", tgt)
 	}
@@ -181,6 +185,7 @@ func externalLibFactory(config *BobConfig) (blueprint.Module, []interface{}) {
 
 	module.Properties.Host.init(&config.Properties, ExternalLibProps{})
 	module.Properties.Target.init(&config.Properties, ExternalLibProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, ExternalLibProps{})
 	return module, []interface{}{&module.Properties, &module.SimpleName.Properties}
 }
 
@@ -136,7 +136,7 @@ func (m *generateLibrary) OutFileTargets() []string {
 //// Support splittable
 
 func (m *generateLibrary) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{m.ModuleGenerateCommon.Properties.Target}
+	return m.ModuleGenerateCommon.supportedVariants()
 }
 
 func (m *generateLibrary) disable() {
@@ -145,7 +145,9 @@ func (m *generateLibrary) disable() {
 }
 
 func (m *generateLibrary) setVariant(variant toolchain.TgtType) {
-	// No need to actually track this, as a single target is always supported
+	// A single target is always supported, but `target` may have been
+	// mapped to the first target variant
+	m.ModuleGenerateCommon.Properties.Target = variant
 }
 
 func (m *generateLibrary) getSplittableProps() *SplittableProps {
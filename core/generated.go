@@ -90,7 +90,7 @@ func getGeneratedFiles(ctx blueprint.ModuleContext) []string {
 func generatedDependerMutator(ctx blueprint.BottomUpMutatorContext) {
 
 	if _, ok := ctx.Module().(*ModuleGenerateSource); ok {
-		GetLogger().Warn(warnings.GenerateRuleWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+		warnModule(ctx, warnings.GenerateRuleWarning)
 	}
 
 	if e, ok := ctx.Module().(enableable); ok {
@@ -108,6 +108,10 @@ func generatedDependerMutator(ctx blueprint.BottomUpMutatorContext) {
 		ctx.AddDependency(ctx.Module(), tag.GeneratedTag, l.Properties.Generated_deps...)
 	}
 
+	if km, ok := ctx.Module().(*ModuleKernelObject); ok {
+		ctx.AddDependency(ctx.Module(), tag.GeneratedHeadersTag, km.Properties.Generated_headers...)
+	}
+
 	// Things that a generated/transformed source depends on
 	if gsc, ok := getGenerateCommon(ctx.Module()); ok {
 		if gsc.Properties.Host_bin != nil {
@@ -94,6 +94,7 @@ func (m *ModuleGenerateCommon) init(properties *config.Properties, list ...inter
 	m.Properties.Features.Init(properties, list...)
 	m.Properties.FlagArgsBuild.Host.init(properties, CommonProps{}, BuildProps{})
 	m.Properties.FlagArgsBuild.Target.init(properties, CommonProps{}, BuildProps{})
+	m.Properties.FlagArgsBuild.VariantSpecific.init(properties, CommonProps{}, BuildProps{})
 }
 
 func (m *ModuleGenerateCommon) shortName() string {
@@ -134,7 +135,7 @@ func (m *ModuleGenerateCommon) getInstallDepPhonyNames(ctx blueprint.ModuleConte
 }
 
 func (m *ModuleGenerateCommon) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{m.Properties.Target}
+	return []toolchain.TgtType{targetVariant(m.Properties.Target)}
 }
 
 func (m *ModuleGenerateCommon) disable() {
@@ -142,9 +143,10 @@ func (m *ModuleGenerateCommon) disable() {
 }
 
 func (m *ModuleGenerateCommon) setVariant(variant toolchain.TgtType) {
-	if variant != m.Properties.Target {
+	if variant != targetVariant(m.Properties.Target) {
 		utils.Die("Variant mismatch: %s != %s", variant, m.Properties.Target)
 	}
+	m.Properties.Target = variant
 }
 
 func (m *ModuleGenerateCommon) getSplittableProps() *SplittableProps {
@@ -222,7 +224,7 @@ func (m *ModuleGenerateCommon) hostBinName(ctx blueprint.ModuleContext) (name st
 func (m *ModuleGenerateCommon) getArgs(ctx blueprint.ModuleContext) (string, map[string]string, []string, toolchain.TgtType) {
 	b := backend.Get()
 
-	tc := b.GetToolchain(m.Properties.Target)
+	tc := getModuleToolchain(ctx, m.Properties.Target)
 	arBinary, _ := tc.GetArchiver()
 	nmBinary, _ := tc.GetNm()
 	ranlibBinary, _ := tc.GetRanlib()
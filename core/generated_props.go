@@ -64,7 +64,8 @@ type GenerateProps struct {
 	// The defaults used to retrieve cflags
 	Flag_defaults []string
 
-	// The target type - must be either "host" or "target"
+	// The target type - must be "host", "target" or one of the variants in
+	// `target_variants`
 	Target toolchain.TgtType
 
 	// If true, depfile name will be generated and can be used as ${depfile} reference in 'cmd'
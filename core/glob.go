@@ -54,13 +54,13 @@ func (m *ModuleGlob) shortName() string {
 
 func (m *ModuleGlob) processPaths(ctx blueprint.BaseModuleContext) {
 	if len(m.Properties.Srcs) == 0 {
-		ctx.PropertyErrorf("srcs", "Missed required property.")
+		propertyErrorf(ctx, "srcs", "Missed required property.")
 		return
 	}
 
 	for _, s := range append(m.Properties.Srcs, m.Properties.Exclude...) {
 		if strings.HasPrefix(filepath.Clean(s), "../") {
-			GetLogger().Warn(warnings.RelativeUpLinkWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+			warnModule(ctx, warnings.RelativeUpLinkWarning)
 		}
 	}
 
@@ -79,7 +79,7 @@ func (m *ModuleGlob) ResolveFiles(ctx blueprint.BaseModuleContext) {
 	}
 
 	if len(files) == 0 && !(*m.Properties.Allow_empty) {
-		ctx.ModuleErrorf("Glob is empty!")
+		propertyErrorf(ctx, "srcs", "Glob is empty! Set allow_empty if this is expected.")
 	}
 
 	m.Properties.Files = files
@@ -75,11 +75,13 @@ func (m *ModuleImportCCBinary) OutFileTargets() (tgts []string) {
 
 // Support Splittable properties
 func (m *ModuleImportCCBinary) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{m.Properties.Target}
+	return []toolchain.TgtType{targetVariant(m.Properties.Target)}
 }
 
 func (m *ModuleImportCCBinary) setVariant(variant toolchain.TgtType) {
-	// No need to actually track this, as a single target is always supported
+	// A single target is always supported, but `target` may have been
+	// mapped to the first target variant
+	m.Properties.Target = variant
 }
 
 func (m *ModuleImportCCBinary) disable() {
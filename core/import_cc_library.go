@@ -129,11 +129,13 @@ func (m *ModuleImportCCLibrary) GenerateBuildActions(ctx blueprint.ModuleContext
 
 // Support Splittable properties
 func (m *ModuleImportCCLibrary) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{m.Properties.Target}
+	return []toolchain.TgtType{targetVariant(m.Properties.Target)}
 }
 
 func (m *ModuleImportCCLibrary) setVariant(variant toolchain.TgtType) {
-	// No need to actually track this, as a single target is always supported
+	// A single target is always supported, but `target` may have been
+	// mapped to the first target variant
+	m.Properties.Target = variant
 }
 
 func (m *ModuleImportCCLibrary) disable() {
@@ -3,7 +3,9 @@ package core
 import (
 	"path/filepath"
 	"regexp"
+	"strconv"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/file"
 	"github.com/ARM-software/bob-build/core/module"
 	"github.com/ARM-software/bob-build/core/tag"
@@ -11,6 +13,7 @@ import (
 	"github.com/ARM-software/bob-build/internal/utils"
 
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
 )
 
 // EnableableProps allow a module to be disabled or only built when explicitly requested
@@ -53,7 +56,7 @@ func isBuiltByDefault(e enableable) bool {
 			tgt = m.getTarget()
 		}
 
-		return tgt == toolchain.TgtTypeTarget
+		return tgt.IsTarget()
 	case *ModuleKernelObject:
 		return true
 	}
@@ -83,8 +86,21 @@ type InstallableProps struct {
 	Post_install_cmd *string
 	// Arguments to post install command
 	Post_install_args []string
+	// Symlinks to the installed file, created relative to its directory
+	Install_symlinks []string
+	// Permissions of the installed files, in octal, overriding the mode of
+	// the install group
+	Install_mode *string
+	// Owner and group of the installed files, recorded in the install
+	// manifest, overriding those of the install group
+	Install_owner       *string
+	Install_owner_group *string
 	// The path retrieved from the install group so we don't need to walk dependencies to get it
 	InstallGroupPath *string `blueprint:"mutated"`
+	// The attributes of the installed files, retrieved from the install group
+	InstallGroupMode  *string `blueprint:"mutated"`
+	InstallGroupOwner *string `blueprint:"mutated"`
+	InstallGroupGroup *string `blueprint:"mutated"`
 }
 
 func (props *InstallableProps) processPaths(ctx blueprint.BaseModuleContext) {
@@ -168,6 +184,24 @@ func getShortNamesForDirectDepsWithTagsForNonFilegroup(ctx blueprint.ModuleConte
 // InstallGroupProps describes the properties of bob_install_group modules
 type InstallGroupProps struct {
 	Install_path *string
+	// Prefix of the install path, as seen on the target filesystem,
+	// e.g. `/usr`
+	Prefix *string
+	// Permissions of the installed files, in octal
+	Mode *string
+	// Owner and group of the installed files, recorded in the install
+	// manifest
+	Owner *string
+	Group *string
+
+	// Layout of debug information installed into this group, `debuglink`
+	// or `build_id`
+	Debug_info_layout *string
+	// Compress the sections of debug information installed into this group
+	Compress_debug_sections *bool
+	// Write an index of the build-ids of the debug information installed
+	// into this group
+	Symbol_index *bool
 }
 
 type ModuleInstallGroup struct {
@@ -183,6 +217,56 @@ func (m *ModuleInstallGroup) GenerateBuildActions(ctx blueprint.ModuleContext) {
 	// No build actions for a bob_install_group
 }
 
+// Returns the path files are installed to. The prefix is only used by the
+// Linux backend, as the Android backends decide where files are installed.
+func (m *ModuleInstallGroup) installPath(ctx configProvider) *string {
+	path := m.Properties.Install_path
+	if _, linux := getConfig(ctx).Generator.(*linuxGenerator); !linux ||
+		path == nil || m.Properties.Prefix == nil {
+		return path
+	}
+
+	prefixed := filepath.Join(*m.Properties.Prefix, *path)
+	return &prefixed
+}
+
+// Whether debug information installed into this group is named after the
+// build-id of each binary
+func (m *ModuleInstallGroup) debugBuildID() bool {
+	return proptools.String(m.Properties.Debug_info_layout) == "build_id"
+}
+
+// Checks the properties of the install group, returning false when any is
+// invalid.
+func (m *ModuleInstallGroup) checkProperties(ctx blueprint.TopDownMutatorContext) bool {
+	valid := true
+
+	if mode := m.Properties.Mode; mode != nil {
+		if perm, err := strconv.ParseUint(*mode, 8, 32); err != nil || perm > 07777 {
+			propertyErrorf(ctx, "mode", "invalid mode %s", *mode)
+			valid = false
+		}
+	}
+
+	switch layout := proptools.StringDefault(m.Properties.Debug_info_layout, "debuglink"); layout {
+	case "debuglink", "build_id":
+	default:
+		propertyErrorf(ctx, "debug_info_layout", "invalid layout %s, expected debuglink or build_id", layout)
+		valid = false
+	}
+
+	// Symbol indexes are only written by the Linux backend
+	if _, linux := getConfig(ctx).Generator.(*linuxGenerator); linux && proptools.Bool(m.Properties.Symbol_index) {
+		path := m.installPath(ctx)
+		if path == nil || *path == "" || !m.debugBuildID() {
+			propertyErrorf(ctx, "symbol_index", "requires install_path and the build_id debug_info_layout")
+			valid = false
+		}
+	}
+
+	return valid
+}
+
 func (m *ModuleInstallGroup) FeaturableProperties() []interface{} {
 	return []interface{}{&m.Properties.InstallGroupProps, &m.Properties.TagableProps}
 }
@@ -355,8 +439,8 @@ func resourceFactory(config *BobConfig) (blueprint.Module, []interface{}) {
 		&module.SimpleName.Properties}
 }
 
-func getInstallGroupPathFromTag(ctx blueprint.TopDownMutatorContext, inputTag tag.DependencyTag) *string {
-	var installGroupPath *string
+func getInstallGroupFromTag(ctx blueprint.TopDownMutatorContext, inputTag tag.DependencyTag) *ModuleInstallGroup {
+	var installGroup *ModuleInstallGroup
 
 	ctx.VisitDirectDepsIf(
 		func(m blueprint.Module) bool { return ctx.OtherModuleDependencyTag(m) == inputTag },
@@ -366,26 +450,59 @@ func getInstallGroupPathFromTag(ctx blueprint.TopDownMutatorContext, inputTag ta
 				utils.Die("%s dependency of %s not an install group",
 					inputTag.Name, ctx.ModuleName())
 			}
-			if installGroupPath != nil {
+			if installGroup != nil {
 				utils.Die("Multiple %s dependencies for %s",
 					inputTag.Name, ctx.ModuleName())
 			}
-			installGroupPath = insg.Properties.Install_path
+			installGroup = insg
 		})
 
-	return installGroupPath
+	return installGroup
 }
 
 func installGroupMutator(ctx blueprint.TopDownMutatorContext) {
+	if insg, ok := ctx.Module().(*ModuleInstallGroup); ok {
+		insg.checkProperties(ctx)
+	}
+
 	if ins, ok := ctx.Module().(installable); ok {
-		path := getInstallGroupPathFromTag(ctx, tag.InstallGroupTag)
+		insg := getInstallGroupFromTag(ctx, tag.InstallGroupTag)
+		if insg == nil {
+			return
+		}
+
+		path := insg.installPath(ctx)
 		if path != nil {
 			if *path == "" {
 				utils.Die("Module %s has empty install path", ctx.ModuleName())
 			}
 
+			// The install group checks its own properties
 			props := ins.getInstallableProps()
 			props.InstallGroupPath = path
+			if m, ok := ctx.Module().(splittable); ok {
+				if dir := backend.Get().InstallVariantDir(m.getTarget()); dir != "" {
+					props.InstallGroupPath = proptools.StringPtr(filepath.Join(*path, dir))
+				}
+			}
+			props.InstallGroupMode = insg.Properties.Mode
+			props.InstallGroupOwner = insg.Properties.Owner
+			props.InstallGroupGroup = insg.Properties.Group
+
+			// The module's own attributes override those of the group
+			if props.Install_mode != nil {
+				if perm, err := strconv.ParseUint(*props.Install_mode, 8, 32); err != nil || perm > 07777 {
+					propertyErrorf(ctx, "install_mode", "invalid mode %s", *props.Install_mode)
+					return
+				}
+				props.InstallGroupMode = props.Install_mode
+			}
+			if props.Install_owner != nil {
+				props.InstallGroupOwner = props.Install_owner
+			}
+			if props.Install_owner_group != nil {
+				props.InstallGroupGroup = props.Install_owner_group
+			}
 		}
 	}
 }
@@ -21,6 +21,8 @@ type KernelProps struct {
 	Kbuild_options []string
 	// Kernel modules which this module depends on
 	Extra_symbols []string
+	// Modules generating headers used by this module, such as `bob_genrule`
+	Generated_headers []string
 	// Arguments to pass to kernel make invocation
 	Make_args []string
 	// Kernel directory location
@@ -35,6 +37,26 @@ type KernelProps struct {
 	Kernel_ld *string
 	// Target triple when using clang as the compiler
 	Kernel_clang_triple *string
+	// Private key signing the module, either a file or a PKCS#11 URI
+	Sign_key *string
+	// X.509 certificate matching the signing key
+	Sign_cert *string
+	// Hash algorithm used for the signature
+	Sign_hash *string
+	// Compression applied to the module, one of `xz`, `zstd` or `gzip`
+	Compress *string
+}
+
+// The extension and command for each supported module compression.
+var kernelModuleCompression = map[string]struct{ ext, command string }{
+	"gzip": {".gz", "gzip -n -9"},
+	"xz":   {".xz", "xz --check=crc32 --lzma2=dict=1MiB"},
+	"zstd": {".zst", "zstd -q -T0"},
+}
+
+// Keys may be held by a PKCS#11 token rather than in a file.
+func isSignKeyFile(key string) bool {
+	return !strings.HasPrefix(key, "pkcs11:")
 }
 
 func (k *KernelProps) processPaths(ctx blueprint.BaseModuleContext) {
@@ -46,6 +68,13 @@ func (k *KernelProps) processPaths(ctx blueprint.BaseModuleContext) {
 		kdir = filepath.Join(prefix, kdir)
 		k.Kernel_dir = proptools.StringPtr(kdir)
 	}
+
+	// join module dir with relative signing key and certificate
+	for _, p := range []*string{k.Sign_key, k.Sign_cert} {
+		if p != nil && *p != "" && isSignKeyFile(*p) && !filepath.IsAbs(*p) {
+			*p = filepath.Join(prefix, *p)
+		}
+	}
 }
 
 type ModuleKernelObject struct {
@@ -73,8 +102,15 @@ type kernelModuleInterface interface {
 
 var _ kernelModuleInterface = (*ModuleKernelObject)(nil) // impl check
 
+// The module is installed once signed and compressed, when requested.
 func (m *ModuleKernelObject) OutFiles() file.Paths {
-	return file.Paths{file.NewPath(m.outputName()+".ko", m.Name(), file.TypeKernelModule|file.TypeInstallable)}
+	name := m.outputName() + ".ko"
+	if c, ok := kernelModuleCompression[proptools.String(m.Properties.Compress)]; ok {
+		name += c.ext
+	} else if m.signed() {
+		name = filepath.Join("signed", name)
+	}
+	return file.Paths{file.NewPath(name, m.Name(), file.TypeKernelModule|file.TypeInstallable)}
 }
 func (m *ModuleKernelObject) OutFileTargets() []string {
 	return []string{}
@@ -151,16 +187,126 @@ func (m *ModuleKernelObject) extraSymbolsModules(ctx blueprint.BaseModuleContext
 	return
 }
 
+// The module as built by Kbuild, before any signing or compression
+func (m *ModuleKernelObject) builtKo() string {
+	return filepath.Join(backend.Get().KernelModOutputDir(), m.outputName(), m.outputName()+".ko")
+}
+
+// The signed module, before any compression
+func (m *ModuleKernelObject) signedKo() string {
+	return filepath.Join(backend.Get().KernelModOutputDir(), m.outputName(), "signed", m.outputName()+".ko")
+}
+
+func (m *ModuleKernelObject) signed() bool {
+	return proptools.String(m.Properties.Sign_key) != ""
+}
+
+// Checks the signing and compression properties are consistent.
+func (m *ModuleKernelObject) checkSigning(ctx blueprint.BaseModuleContext) bool {
+	valid := true
+	key := proptools.String(m.Properties.Sign_key)
+	cert := proptools.String(m.Properties.Sign_cert)
+	if (key == "") != (cert == "") {
+		moduleErrorf(ctx, "must set both sign_key and sign_cert")
+		valid = false
+	}
+	if key == "" && m.Properties.Sign_hash != nil {
+		propertyErrorf(ctx, "sign_hash", "is set without sign_key")
+		valid = false
+	}
+	if c := m.Properties.Compress; c != nil {
+		if _, ok := kernelModuleCompression[*c]; !ok {
+			propertyErrorf(ctx, "compress", "invalid compression %s, expected xz, zstd or gzip", *c)
+			valid = false
+		}
+	}
+	return valid
+}
+
+// The symbols exported by the module, written by Kbuild alongside it
+func (m *ModuleKernelObject) symversFile() string {
+	return filepath.Join(backend.Get().KernelModOutputDir(), m.outputName(), "Module.symvers")
+}
+
 func (m *ModuleKernelObject) extraSymbolsFiles(ctx blueprint.BaseModuleContext) (files []string) {
+	for _, km := range m.extraSymbolsModules(ctx) {
+		files = append(files, km.symversFile())
+	}
+	return
+}
+
+// Returns the headers generated for the module, and the directories to
+// search for them.
+func (m *ModuleKernelObject) generatedHeaders(ctx blueprint.BaseModuleContext) (dirs []string, headers []string) {
 	ctx.VisitDirectDepsIf(
-		func(m blueprint.Module) bool { return ctx.OtherModuleDependencyTag(m) == tag.KernelModuleTag },
+		func(m blueprint.Module) bool { return ctx.OtherModuleDependencyTag(m) == tag.GeneratedHeadersTag },
 		func(m blueprint.Module) {
-			path := filepath.Join(backend.Get().KernelModOutputDir(), m.Name(), "Module.symvers")
-			files = append(files, path)
+			gs, ok := m.(dependentInterface)
+			if !ok {
+				propertyErrorf(ctx, "generated_headers", "%s does not have outputs", ctx.OtherModuleName(m))
+				return
+			}
+			dirs = append(dirs, backend.Get().SourceOutputDir(m))
+			headers = append(headers, file.GetOutputs(gs)...)
+			headers = append(headers, file.GetImplicitOutputs(gs)...)
 		})
 	return
 }
 
+// Returns the content of the Kbuild file building the module from its
+// sources. Modules listing their own Kbuild or Makefile in `srcs` keep
+// using it, and modules whose sources cannot be built by a generated
+// Kbuild report an error, in which case false is returned.
+func (m *ModuleKernelObject) generateKbuild(ctx blueprint.BaseModuleContext) (string, bool) {
+	objs := []string{}
+	provided := false
+	valid := true
+
+	m.Properties.GetFiles(ctx).ForEach(
+		func(fp file.Path) bool {
+			switch filepath.Base(fp.ScopedPath()) {
+			case "Kbuild", "Makefile":
+				provided = true
+				return false
+			}
+			if fp.IsNotType(file.TypeC) && fp.IsNotType(file.TypeAsm) {
+				return true
+			}
+
+			// Sources are copied to the output tree relative to the
+			// module's directory, where Kbuild is run.
+			rel, err := filepath.Rel(projectModuleDir(ctx), fp.ScopedPath())
+			if err != nil || strings.HasPrefix(rel, "..") {
+				propertyErrorf(ctx, "srcs", "source %s is outside the directory of the module", fp.ScopedPath())
+				valid = false
+				return true
+			}
+			objs = append(objs, strings.TrimSuffix(rel, fp.Ext())+".o")
+			return true
+		})
+
+	if provided || !valid {
+		return "", false
+	}
+	if len(objs) == 0 {
+		propertyErrorf(ctx, "srcs", "has no sources to build")
+		return "", false
+	}
+
+	name := m.outputName()
+	var sb strings.Builder
+	sb.WriteString("# Generated by Bob for the " + name + " kernel module\n")
+	sb.WriteString("obj-m := " + name + ".o\n")
+	if len(objs) > 1 || objs[0] != name+".o" {
+		if utils.Contains(objs, name+".o") {
+			propertyErrorf(ctx, "srcs", "has several sources, so none can be named %s.c", name)
+			return "", false
+		}
+		sb.WriteString(name + "-y := " + strings.Join(objs, " ") + "\n")
+	}
+	return sb.String(), true
+}
+
 func (m *ModuleKernelObject) HasTagRegex(query *regexp.Regexp) bool {
 	return m.Properties.TagableProps.HasTagRegex(query)
 }
@@ -224,6 +370,11 @@ func (m *ModuleKernelObject) generateKbuildArgs(ctx blueprint.BaseModuleContext)
 		extraIncludePaths = append(extraIncludePaths, includeDir)
 	}
 
+	generatedDirs, _ := m.generatedHeaders(ctx)
+	for _, includeDir := range generatedDirs {
+		extraIncludePaths = append(extraIncludePaths, "-I"+includeDir)
+	}
+
 	kmodBuild := getBackendPathInBobScriptsDir(getGenerator(ctx), "kmod_build.py")
 	kdir := proptools.String(m.Properties.KernelProps.Kernel_dir)
 	if kdir != "" && !filepath.IsAbs(kdir) {
@@ -8,7 +8,6 @@ import (
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/pathtools"
 
-	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/file"
 	"github.com/ARM-software/bob-build/core/toolchain"
 	"github.com/ARM-software/bob-build/internal/utils"
@@ -184,7 +183,7 @@ func verifyMatchSources(ctx blueprint.BaseModuleContext, matchedNonCompiledSourc
 	}
 
 	if unmatchedCount > 0 {
-		GetLogger().Warn(warnings.UnmatchedNonCompileSrcsWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+		warnModule(ctx, warnings.UnmatchedNonCompileSrcsWarning)
 	}
 }
 
@@ -206,7 +205,7 @@ func setupAddIfSupported(ctx blueprint.BaseModuleContext,
 
 	if t, ok := ctx.Module().(moduleWithBuildProps); ok {
 		build := t.build()
-		tc := backend.Get().GetToolchain(build.TargetType)
+		tc := getModuleToolchain(ctx, build.TargetType)
 
 		addtoFuncmap(propfnmap, []string{"Cflags", "Export_cflags"}, "add_if_supported",
 			func(s string) string {
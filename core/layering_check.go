@@ -0,0 +1,80 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// Whether the sources of a strict module are checked to only include the
+// headers of its direct dependencies.
+func layeringCheckEnabled(ctx configProvider, m *ModuleStrictLibrary) bool {
+	return proptools.BoolDefault(m.Properties.Layering_check,
+		getConfig(ctx).Properties.GetBool("layering_check"))
+}
+
+// A header listed in the `hdrs` of a library.
+type ownedHeader struct {
+	owner string
+	path  string
+}
+
+// Returns the names of the direct dependencies of a strict module, and the
+// headers of every library it depends on, directly or not. Only these
+// headers are reachable through the include paths the module is compiled
+// with.
+func layeringCheckDeps(ctx blueprint.ModuleContext) (direct []string, hdrs []ownedHeader) {
+	ctx.VisitDirectDepsIf(
+		func(dep blueprint.Module) bool {
+			return ctx.OtherModuleDependencyTag(dep) == tag.DepTag
+		},
+		func(dep blueprint.Module) {
+			direct = append(direct, dep.Name())
+		})
+
+	visited := map[string]bool{}
+	ctx.VisitDepsDepthFirstIf(
+		func(dep blueprint.Module) bool {
+			return ctx.OtherModuleDependencyTag(dep) == tag.DepTag
+		},
+		func(dep blueprint.Module) {
+			lib, ok := dep.(*ModuleStrictLibrary)
+			if !ok || visited[lib.Name()] {
+				return
+			}
+			visited[lib.Name()] = true
+			for _, h := range lib.Properties.Hdrs {
+				// Generated headers are not owned by a source path
+				if strings.HasPrefix(h, ":") {
+					continue
+				}
+				hdrs = append(hdrs, ownedHeader{lib.Name(), filepath.Join("${SrcDir}", h)})
+			}
+		})
+
+	sort.Strings(direct)
+	sort.Slice(hdrs, func(i, j int) bool {
+		if hdrs[i].owner != hdrs[j].owner {
+			return hdrs[i].owner < hdrs[j].owner
+		}
+		return hdrs[i].path < hdrs[j].path
+	})
+	return
+}
+
+// Whether any of the headers belongs to a library which is not a direct
+// dependency, in which case including it would be a layering violation.
+func hasIndirectHeaders(direct []string, hdrs []ownedHeader) bool {
+	for _, h := range hdrs {
+		if !utils.Contains(direct, h.owner) {
+			return true
+		}
+	}
+	return false
+}
@@ -36,7 +36,7 @@ func (s *LegacySourceProps) processPaths(ctx blueprint.BaseModuleContext) {
 
 	for _, src := range s.Srcs {
 		if strings.HasPrefix(filepath.Clean(src), "../") {
-			GetLogger().Warn(warnings.RelativeUpLinkWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+			warnModule(ctx, warnings.RelativeUpLinkWarning)
 		}
 	}
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/file"
 	"github.com/ARM-software/bob-build/core/flag"
 	"github.com/ARM-software/bob-build/core/module"
@@ -156,7 +157,7 @@ func (m *ModuleLibrary) supportedVariants() (tgts []toolchain.TgtType) {
 		tgts = append(tgts, toolchain.TgtTypeHost)
 	}
 	if m.Properties.isTargetSupported() {
-		tgts = append(tgts, toolchain.TgtTypeTarget)
+		tgts = append(tgts, backend.Get().TargetVariants()...)
 	}
 	return
 }
@@ -197,6 +198,14 @@ func (m *ModuleLibrary) setDebugPath(path *string) {
 	m.Properties.setDebugPath(path)
 }
 
+func (m *ModuleLibrary) getDebugInfoLayout() (bool, bool) {
+	return m.Properties.getDebugInfoLayout()
+}
+
+func (m *ModuleLibrary) setDebugInfoLayout(buildID bool, compress bool) {
+	m.Properties.setDebugInfoLayout(buildID, compress)
+}
+
 func (m *ModuleLibrary) stripOutputDir(g generatorBackend) string {
 	return getBackendPathInBuildDir(g, string(m.Properties.TargetType), "strip")
 }
@@ -482,6 +491,18 @@ func (m *ModuleLibrary) GetMteProps(blueprint.ModuleContext) AndroidMTEProps {
 	return m.Properties.AndroidMTEProps
 }
 
+func (m *ModuleLibrary) getSanitizeProps() *SanitizeProps {
+	return &m.Properties.Build.SanitizeProps
+}
+
+func (m *ModuleLibrary) getCoverageProps() *CoverageProps {
+	return &m.Properties.Build.CoverageProps
+}
+
+func (m *ModuleLibrary) getPackageConfigProps() *PackageConfigProps {
+	return &m.Properties.Build.PackageConfigProps
+}
+
 func (m *ModuleLibrary) IsHwAsanEnabled() bool {
 	return proptools.Bool(m.Properties.Build.Hwasan_enabled)
 }
@@ -538,9 +559,9 @@ func (m *ModuleLibrary) processPaths(ctx blueprint.BaseModuleContext) {
 	}
 }
 
-func (m *ModuleLibrary) checkField(cond bool, fieldName string) {
+func (m *ModuleLibrary) checkField(ctx blueprint.BaseModuleContext, cond bool, fieldName string) {
 	if !cond {
-		utils.Die("%s has field %s set", m.Name(), fieldName)
+		propertyErrorf(ctx, fieldName, "has field %s set", fieldName)
 	}
 }
 
@@ -577,6 +598,10 @@ func (m *ModuleLibrary) LibraryFactory(config *BobConfig, module blueprint.Modul
 		CommonProps{},
 		BuildProps{},
 		TagableProps{})
+	m.Properties.VariantSpecific.init(&config.Properties,
+		CommonProps{},
+		BuildProps{},
+		TagableProps{})
 
 	return module, []interface{}{&m.Properties, &m.SimpleName.Properties}
 }
@@ -609,27 +634,28 @@ func checkLibraryFieldsMutator(ctx blueprint.BottomUpMutatorContext) {
 	m := ctx.Module()
 	if b, ok := m.(*ModuleBinary); ok {
 		props := b.Properties
-		b.checkField(len(props.Export_cflags) == 0, "export_cflags")
-		b.checkField(len(props.Export_include_dirs) == 0, "export_include_dirs")
-		b.checkField(len(props.Export_ldflags) == 0, "export_ldflags")
-		b.checkField(len(props.Export_local_include_dirs) == 0, "export_local_include_dirs")
-		b.checkField(len(props.Export_local_system_include_dirs) == 0, "export_local_system_include_dirs")
-		b.checkField(len(props.Export_system_include_dirs) == 0, "export_system_include_dirs")
-		b.checkField(len(props.Reexport_libs) == 0, "reexport_libs")
-		b.checkField(props.Forwarding_shlib == nil, "forwarding_shlib")
+		b.checkField(ctx, len(props.Export_cflags) == 0, "export_cflags")
+		b.checkField(ctx, len(props.Export_include_dirs) == 0, "export_include_dirs")
+		b.checkField(ctx, len(props.Export_ldflags) == 0, "export_ldflags")
+		b.checkField(ctx, len(props.Export_local_include_dirs) == 0, "export_local_include_dirs")
+		b.checkField(ctx, len(props.Export_local_system_include_dirs) == 0, "export_local_system_include_dirs")
+		b.checkField(ctx, len(props.Export_system_include_dirs) == 0, "export_system_include_dirs")
+		b.checkField(ctx, len(props.Reexport_libs) == 0, "reexport_libs")
+		b.checkField(ctx, props.Forwarding_shlib == nil, "forwarding_shlib")
+		b.checkField(ctx, !props.PackageConfigProps.isSet(), "package_config")
 	} else if sl, ok := m.(*ModuleSharedLibrary); ok {
 		props := sl.Properties
 		if !sl.isExternal() {
-			sl.checkField(len(props.Export_ldflags) == 0, "export_ldflags")
+			sl.checkField(ctx, len(props.Export_ldflags) == 0, "export_ldflags")
 		}
-		sl.checkField(props.Mte.Memtag_heap == nil, "memtag_heap")
-		sl.checkField(props.Mte.Diag_memtag_heap == nil, "memtag_heap")
+		sl.checkField(ctx, props.Mte.Memtag_heap == nil, "memtag_heap")
+		sl.checkField(ctx, props.Mte.Diag_memtag_heap == nil, "memtag_heap")
 	} else if sl, ok := m.(*ModuleStaticLibrary); ok {
 		props := sl.Properties
-		sl.checkField(props.Forwarding_shlib == nil, "forwarding_shlib")
-		sl.checkField(props.Version_script == nil, "version_script")
-		sl.checkField(props.Mte.Memtag_heap == nil, "memtag_heap")
-		sl.checkField(props.Mte.Diag_memtag_heap == nil, "memtag_heap")
+		sl.checkField(ctx, props.Forwarding_shlib == nil, "forwarding_shlib")
+		sl.checkField(ctx, props.Version_script == nil, "version_script")
+		sl.checkField(ctx, props.Mte.Memtag_heap == nil, "memtag_heap")
+		sl.checkField(ctx, props.Mte.Diag_memtag_heap == nil, "memtag_heap")
 	}
 }
 
@@ -643,7 +669,7 @@ func checkReexportLibsMutator(ctx blueprint.TopDownMutatorContext) {
 				l.Properties.Header_libs,
 				l.Properties.Whole_static_libs,
 				l.Properties.Export_header_libs) {
-				utils.Die("%s re-exports unused library %s", ctx.ModuleName(), lib)
+				propertyErrorf(ctx, "reexport_libs", "re-exports unused library %s", lib)
 			}
 		}
 	}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/internal/warnings"
+)
+
+// In lint mode, only the mutators loading the module definitions run, up to
+// `collect_metadata`. The warnings are reported along with the checks
+// below, then Bob exits without writing any build files.
+var lintMode = false
+
+var (
+	// The checks run in a single threaded mutator, so these are not locked.
+	lintDefaults     = map[string]lintModule{}
+	lintFlagDefaults = map[string]bool{}
+	lintWarned       = map[string]bool{}
+	lintOnce         sync.Once
+)
+
+type lintModule struct {
+	bpFile string
+	tags   []string
+}
+
+// Lint is the entry point for `bob lint`.
+func Lint() {
+	lintMode = true
+	Main()
+}
+
+// lintWarn raises a warning once per module, rather than once per variant.
+func lintWarn(ctx blueprint.BaseModuleContext, category warnings.Category, args ...interface{}) {
+	key := ctx.ModuleName() + "\x00" + string(category) + "\x00" + fmt.Sprint(args...)
+	if lintWarned[key] {
+		return
+	}
+	lintWarned[key] = true
+	warnModule(ctx, category, args...)
+}
+
+// duplicates returns the entries listed more than once, in the order of
+// their first repetition.
+func duplicates(list []string) []string {
+	seen := map[string]int{}
+	dups := []string{}
+	for _, s := range list {
+		seen[s]++
+		if seen[s] == 2 {
+			dups = append(dups, s)
+		}
+	}
+	return dups
+}
+
+// unreferencedDeps returns the `generated_deps` whose outputs are not used
+// in `text`, as `${name_out}` or `$name_out`.
+func unreferencedDeps(text string, deps []string) []string {
+	unused := []string{}
+	for _, dep := range deps {
+		// Drop any variant, e.g. `:host`
+		name := strings.SplitN(dep, ":", 2)[0]
+		re := regexp.MustCompile(`\$(\{` + regexp.QuoteMeta(name) + `_out\}|` +
+			regexp.QuoteMeta(name) + `_out([^a-zA-Z0-9_-]|$))`)
+		if !re.MatchString(text) {
+			unused = append(unused, name)
+		}
+	}
+	return unused
+}
+
+func lintSrcs(m blueprint.Module) []string {
+	switch m := m.(type) {
+	case *ModuleDefaults:
+		// Reported as DefaultSrcsWarning
+		return nil
+	case matchSourceInterface:
+		return m.getLegacySourceProperties().Srcs
+	case *ModuleFilegroup:
+		return m.Properties.Srcs
+	case *ModuleStrictLibrary:
+		return m.Properties.Srcs
+	}
+	return nil
+}
+
+func lintMutator(ctx blueprint.BottomUpMutatorContext) {
+	if d, ok := ctx.Module().(*ModuleDefaults); ok {
+		lintDefaults[ctx.ModuleName()] = lintModule{ctx.BlueprintsFile(), d.Properties.GetTags()}
+		return
+	}
+
+	gsc, isGenerator := getGenerateCommon(ctx.Module())
+	if isGenerator {
+		for _, d := range gsc.Properties.Flag_defaults {
+			lintFlagDefaults[d] = true
+		}
+	}
+
+	if e, ok := ctx.Module().(enableable); ok {
+		if !isEnabled(e) {
+			return
+		}
+	}
+
+	for _, src := range duplicates(lintSrcs(ctx.Module())) {
+		lintWarn(ctx, warnings.DuplicateSrcsWarning, strings.TrimPrefix(src, projectModuleDir(ctx)+"/"))
+	}
+
+	if isGenerator {
+		text := strings.Join(append([]string{proptools.String(gsc.Properties.Cmd),
+			proptools.String(gsc.Properties.Rsp_content)}, gsc.Properties.Args...), " ")
+		for _, dep := range unreferencedDeps(text, gsc.Properties.Generated_deps) {
+			lintWarn(ctx, warnings.UnreferencedDepWarning, dep)
+		}
+	}
+}
+
+// reportUnusedDefaults warns about the defaults which neither modules nor
+// other defaults use.
+func reportUnusedDefaults() {
+	used := map[string]bool{}
+
+	defaultsMapLock.RLock()
+	for _, defaults := range defaultsMap {
+		for _, d := range defaults {
+			used[d] = true
+		}
+	}
+	defaultsMapLock.RUnlock()
+
+	for d := range lintFlagDefaults {
+		used[d] = true
+	}
+
+	names := []string{}
+	for name := range lintDefaults {
+		if !used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := lintDefaults[name]
+		logger.WarnTagged(warnings.UnusedDefaultsWarning, d.bpFile, name, d.tags)
+	}
+}
+
+// lintReportMutator runs once the checks are done. It reports the errors
+// and the logger's results, and exits before any build actions are
+// generated.
+func lintReportMutator(ctx blueprint.BottomUpMutatorContext) {
+	lintOnce.Do(func() {
+		reportUnusedDefaults()
+		TearDownLogger()
+		os.Exit(0)
+	})
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lint_duplicates(t *testing.T) {
+	assert.Equal(t, []string{}, duplicates([]string{"src/a.c", "src/b.c"}))
+	assert.Equal(t, []string{"src/b.c", "src/a.c"},
+		duplicates([]string{"src/a.c", "src/b.c", "src/b.c", "src/a.c", "src/b.c"}))
+}
+
+func Test_lint_unreferenced_deps(t *testing.T) {
+	cmd := "${tool} --table ${gen_table_out} -I $gen_headers_out -o ${out}"
+
+	assert.Equal(t, []string{}, unreferencedDeps(cmd, []string{"gen_table", "gen_headers"}))
+	// Variants are not part of the variable name
+	assert.Equal(t, []string{}, unreferencedDeps(cmd, []string{"gen_table:host"}))
+	assert.Equal(t, []string{"gen", "gen_headers2"}, unreferencedDeps(cmd, []string{"gen", "gen_headers2"}))
+}
@@ -113,7 +113,7 @@ var tocRule = pctx.StaticRule("shared_library_toc",
 	"tocflags")
 
 func (g *linuxGenerator) addSharedLibToc(ctx blueprint.ModuleContext, soFile, tocFile string, tgt toolchain.TgtType) {
-	tc := backend.Get().GetToolchain(tgt)
+	tc := getModuleToolchain(ctx, tgt)
 	tocFlags := tc.GetLibraryTocFlags()
 
 	ctx.Build(pctx,
@@ -169,6 +169,22 @@ var installRule = pctx.StaticRule("install",
 		Description: "$out",
 	})
 
+var installModeRule = pctx.StaticRule("install_mode",
+	blueprint.RuleParams{
+		Command:     "rm -f $out; cp $in $out; chmod $mode $out",
+		Description: "$out",
+	}, "mode")
+
+// Returns the directory which install paths are relative to. This is the
+// staging directory when INSTALL_DESTDIR is set, and the build directory
+// otherwise.
+func linuxInstallRoot(ctx configProvider) string {
+	if destdir := getConfig(ctx).Properties.GetString("install_destdir"); destdir != "" {
+		return destdir
+	}
+	return "${BuildDir}"
+}
+
 func (g *linuxGenerator) install(m interface{}, ctx blueprint.ModuleContext) []string {
 	ins := m.(installable)
 
@@ -177,17 +193,27 @@ func (g *linuxGenerator) install(m interface{}, ctx blueprint.ModuleContext) []s
 	if !ok {
 		return []string{}
 	}
-	installPath = filepath.Join("${BuildDir}", installPath)
+	installPath = filepath.Join(linuxInstallRoot(ctx), installPath)
 
 	installedFiles := []string{}
+	// Files which are copied, rather than symlinked
+	copiedFiles := []string{}
 
 	rule := installRule
 	args := map[string]string{}
 	deps := []string{}
+	if props.InstallGroupMode != nil {
+		rule = installModeRule
+		args["mode"] = *props.InstallGroupMode
+	}
 	if props.Post_install_cmd != nil {
 		rulename := "install"
 
-		cmd := "rm -f $out; cp $in $out ; " + *props.Post_install_cmd
+		cmd := "rm -f $out; cp $in $out ; "
+		if props.InstallGroupMode != nil {
+			cmd += "chmod $mode $out ; "
+		}
+		cmd += *props.Post_install_cmd
 
 		// Expand args immediately
 		cmd = strings.Replace(cmd, "${args}", strings.Join(props.Post_install_args, " "), -1)
@@ -224,6 +250,7 @@ func (g *linuxGenerator) install(m interface{}, ctx blueprint.ModuleContext) []s
 						})
 
 					installedFiles = append(installedFiles, symlink)
+					recordInstalledFile(ctx, props, symlink)
 				} else {
 					src := fp.BuildPath()
 					dest := filepath.Join(installPath, filepath.Base(src))
@@ -236,34 +263,42 @@ func (g *linuxGenerator) install(m interface{}, ctx blueprint.ModuleContext) []s
 
 							debugPathPrefix := installPath //Default to install path
 							if separateDebugInfo && *debugPath != "" {
-								debugPathPrefix = filepath.Join("${BuildDir}", *debugPath)
+								debugPathPrefix = filepath.Join(linuxInstallRoot(ctx), *debugPath)
 							}
 
 							if lib.strip() || separateDebugInfo {
-								tc := backend.Get().GetToolchain(lib.getTarget())
+								tc := getModuleToolchain(ctx, lib.getTarget())
 								basename := filepath.Base(src)
 								strippedSrc := filepath.Join(lib.stripOutputDir(g), basename)
 								stArgs := tc.GetStripFlags()
 								if lib.strip() {
 									stArgs = append(stArgs, "--strip")
 								}
+								buildIDFile := ""
 								if separateDebugInfo {
 									// TODO: This should really be using file interface when enabled
-									dbgFile := filepath.Join(debugPathPrefix, basename+".dbg")
-									stArgs = append(stArgs, "--debug-file")
-									stArgs = append(stArgs, dbgFile)
+									stArgs = append(stArgs, debugInfoArgs(lib, debugPathPrefix, basename)...)
+									if buildID, _ := lib.getDebugInfoLayout(); buildID {
+										// Records the build-id for the symbol index
+										buildIDFile = strippedSrc + ".build-id"
+										stArgs = append(stArgs, "--build-id-file", buildIDFile)
+									}
 								}
 								stripArgs := map[string]string{
 									"args": strings.Join(stArgs, " "),
 								}
-								ctx.Build(pctx,
-									blueprint.BuildParams{
-										Rule:     stripRule,
-										Outputs:  []string{strippedSrc},
-										Inputs:   []string{src},
-										Args:     stripArgs,
-										Optional: true,
-									})
+								buildParams := blueprint.BuildParams{
+									Rule:     stripRule,
+									Outputs:  []string{strippedSrc},
+									Inputs:   []string{src},
+									Args:     stripArgs,
+									Optional: true,
+								}
+								if buildIDFile != "" {
+									buildParams.ImplicitOutputs = []string{buildIDFile}
+									recordSymbolIndexEntry(*lib.getDebugInfo(), dest, buildIDFile)
+								}
+								ctx.Build(pctx, buildParams)
 								src = strippedSrc
 							}
 						}
@@ -280,11 +315,40 @@ func (g *linuxGenerator) install(m interface{}, ctx blueprint.ModuleContext) []s
 						})
 
 					installedFiles = append(installedFiles, dest)
+					copiedFiles = append(copiedFiles, dest)
+					recordInstalledFile(ctx, props, dest)
 				}
 				return true
 			})
 	}
 
+	if len(props.Install_symlinks) > 0 && len(copiedFiles) != 1 {
+		propertyErrorf(ctx, "install_symlinks", "the module installs %d files, so the target is ambiguous",
+			len(copiedFiles))
+	} else if len(props.Install_symlinks) > 0 {
+		target := copiedFiles[0]
+
+		for _, link := range props.Install_symlinks {
+			symlink := filepath.Join(filepath.Dir(target), link)
+			rel, err := filepath.Rel(filepath.Dir(symlink), target)
+			if err != nil {
+				propertyErrorf(ctx, "install_symlinks", "invalid symlink %s: %s", link, err)
+				continue
+			}
+			ctx.Build(pctx,
+				blueprint.BuildParams{
+					Rule:     symlinkRule,
+					Outputs:  []string{symlink},
+					Inputs:   []string{target},
+					Args:     map[string]string{"target": rel},
+					Optional: true,
+				})
+
+			installedFiles = append(installedFiles, symlink)
+			recordInstalledFile(ctx, props, symlink)
+		}
+	}
+
 	return append(installedFiles, ins.getInstallDepPhonyNames(ctx)...)
 }
 
@@ -85,24 +85,44 @@ func (g *linuxGenerator) CompileObjs(l Compilable, ctx blueprint.ModuleContext,
 		},
 	)
 
-	ctx.Variable(pctx, "asflags", utils.Join(astargetflags, asflagsList))
-	ctx.Variable(pctx, "cflags", utils.Join(cctargetflags, cflagsList))
-	ctx.Variable(pctx, "conlyflags", strings.Join(ccflagsList, " "))
-	ctx.Variable(pctx, "cxxflags", utils.Join(cxxtargetflags, cxxflagsList))
+	sanitizeFlags := sanitizerCompileFlags(ctx, tc, GetModuleBackendConfiguration(ctx, l))
+	coverageFlags := coverageCompileFlags(ctx, tc, l)
+
+	asflags := utils.Join(astargetflags, asflagsList)
+	cflags := utils.Join(cctargetflags, cflagsList, sanitizeFlags, coverageFlags)
+	conlyflags := strings.Join(ccflagsList, " ")
+	cxxflags := utils.Join(cxxtargetflags, cxxflagsList)
+
+	ctx.Variable(pctx, "asflags", asflags)
+	ctx.Variable(pctx, "cflags", cflags)
+	ctx.Variable(pctx, "conlyflags", conlyflags)
+	ctx.Variable(pctx, "cxxflags", cxxflags)
 
 	objectFiles := []string{}
 	nonCompiledDeps := []string{}
+	tidyStamps := []string{}
+	coverageNotes := []string{}
+	coverageSources := []string{}
+
+	t, tidy := l.(tidyable)
+	tidy = tidy && t.getTidyProps().tidyEnabled()
+
+	coverage := isCoverageInstrumented(ctx, l)
 
 	// TODO: use tags here instead of extensions
 	l.GetFiles(ctx).ForEach(
 		func(source file.Path) bool {
 			var rule blueprint.Rule
+			var compileCmd []string
+			var tidyFlags string
+			var instrumented bool
 			args := make(map[string]string)
 			switch source.Ext() {
 			case ".s":
 				args["ascompiler"] = as
 				args["asflags"] = "$asflags"
 				rule = asRule
+				compileCmd = []string{as, asflags}
 			case ".S":
 				// Assembly with .S suffix must be preprocessed by the C compiler
 				fallthrough
@@ -111,6 +131,11 @@ func (g *linuxGenerator) CompileObjs(l Compilable, ctx blueprint.ModuleContext,
 				args["cflags"] = "$cflags"
 				args["conlyflags"] = "$conlyflags"
 				rule = ccRule
+				compileCmd = []string{cc, "-c", cflags, conlyflags}
+				if source.Ext() == ".c" {
+					tidyFlags = "$cflags $conlyflags"
+					instrumented = coverage
+				}
 			case ".cc":
 				fallthrough
 			case ".cpp":
@@ -118,6 +143,9 @@ func (g *linuxGenerator) CompileObjs(l Compilable, ctx blueprint.ModuleContext,
 				args["cflags"] = "$cflags"
 				args["cxxflags"] = "$cxxflags"
 				rule = cxxRule
+				compileCmd = []string{cxx, "-c", cflags, cxxflags}
+				tidyFlags = "$cflags $cxxflags"
+				instrumented = coverage
 			default:
 				nonCompiledDeps = append(nonCompiledDeps, source.BuildPath())
 				return true
@@ -134,20 +162,46 @@ func (g *linuxGenerator) CompileObjs(l Compilable, ctx blueprint.ModuleContext,
 
 			output := g.ObjDir(l) + source.RelBuildPath() + ".o"
 
+			implicitOuts := []string{}
+			if instrumented {
+				notes := linuxCoverageNotes(output)
+				implicitOuts = append(implicitOuts, notes)
+				coverageNotes = append(coverageNotes, notes)
+				coverageSources = append(coverageSources, source.BuildPath())
+			}
+
 			ctx.Build(pctx,
 				blueprint.BuildParams{
-					Rule:      rule,
-					Outputs:   []string{output},
-					Inputs:    []string{source.BuildPath()},
-					Args:      args,
-					OrderOnly: utils.NewStringSlice(orderOnly, buildWrapperDeps),
-					Optional:  true,
+					Rule:            rule,
+					Outputs:         []string{output},
+					ImplicitOutputs: implicitOuts,
+					Inputs:          []string{source.BuildPath()},
+					Args:            args,
+					OrderOnly:       utils.NewStringSlice(orderOnly, buildWrapperDeps),
+					Optional:        true,
 				})
 			objectFiles = append(objectFiles, output)
 
+			if compileCommandsEnabled() {
+				addCompileCommand(source.BuildPath(), output,
+					compileCmd, []string{source.BuildPath(), "-o", output})
+			}
+
+			if tidy && tidyFlags != "" {
+				tidyStamps = append(tidyStamps, g.tidyAction(t, ctx, source, tidyFlags, orderOnly))
+			}
+
 			return true
 		})
 
+	if tidy {
+		g.tidyPhony(t, ctx, tidyStamps)
+	}
+
+	if coverage {
+		g.coverageModuleAction(l, ctx, tc, coverageNotes, coverageSources)
+	}
+
 	return objectFiles, nonCompiledDeps
 }
 
@@ -271,7 +325,7 @@ func (g *linuxGenerator) ArchivableActions(ctx blueprint.ModuleContext,
 
 func (g *linuxGenerator) staticActions(m *ModuleStaticLibrary, ctx blueprint.ModuleContext) {
 	// Calculate and record outputs
-	tc := backend.Get().GetToolchain(m.Properties.TargetType)
+	tc := getModuleToolchain(ctx, m.Properties.TargetType)
 
 	// The archiver rules do not allow adding arguments that the user can
 	// set, so does not support nonCompiledDeps
@@ -280,12 +334,13 @@ func (g *linuxGenerator) staticActions(m *ModuleStaticLibrary, ctx blueprint.Mod
 	g.ArchivableActions(ctx, m, tc, objectFiles)
 
 	installDeps := append(g.install(m, ctx), file.GetOutputs(m)...)
+	installDeps = append(installDeps, g.packageConfigActions(&m.ModuleLibrary, ctx, m.outputFileName())...)
 	addPhony(m, ctx, installDeps, !isBuiltByDefault(m))
 
 }
 
 func (g *linuxGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx blueprint.ModuleContext) {
-	tc := backend.Get().GetToolchain(m.Properties.TargetType)
+	tc := getModuleToolchain(ctx, m.Properties.TargetType)
 
 	objs, implicits := g.CompileObjs(m, ctx, tc)
 
@@ -296,6 +351,7 @@ func (g *linuxGenerator) strictLibraryActions(m *ModuleStrictLibrary, ctx bluepr
 
 	installDeps := append(g.install(m, ctx), file.GetOutputs(m)...)
 	installDeps = append(installDeps, g.SharedSymlinkActions(ctx, m)...)
+	installDeps = append(installDeps, g.layeringCheckActions(m, ctx, tc)...)
 
 	addPhony(m, ctx, installDeps, !isBuiltByDefault(m))
 }
@@ -364,7 +420,7 @@ func (g *linuxGenerator) getSharedLibFlags(m BackendCommonLibraryInterface, ctx
 	useNoAsNeeded := !m.IsForwardingSharedLibrary()
 	hasForwardingLib := false
 	libPaths := []string{}
-	tc := backend.Get().GetToolchain(m.getTarget())
+	tc := getModuleToolchain(ctx, m.getTarget())
 
 	ctx.VisitDirectDepsIf(
 		func(m blueprint.Module) bool { return ctx.OtherModuleDependencyTag(m) == tag.SharedTag },
@@ -463,7 +519,7 @@ type BackendCommonSharedLibraryInterface interface {
 }
 
 func (g *linuxGenerator) getCommonLibArgs(m BackendCommonLibraryInterface, ctx blueprint.ModuleContext) map[string]string {
-	tc := backend.Get().GetToolchain(m.getTarget())
+	tc := getModuleToolchain(ctx, m.getTarget())
 
 	ldflags := m.FlagsIn().Filtered(func(f flag.Flag) bool {
 		return f.MatchesType(flag.TypeLinker)
@@ -493,6 +549,9 @@ func (g *linuxGenerator) getCommonLibArgs(m BackendCommonLibraryInterface, ctx b
 		ldflags = append(ldflags, tc.GetLinker().SetVersionScript(*versionScript))
 	}
 
+	ldflags = append(ldflags, sanitizerLinkFlags(ctx, tc, GetModuleBackendConfiguration(ctx, m))...)
+	ldflags = append(ldflags, coverageLinkFlags(ctx, tc)...)
+
 	sharedLibLdlibs, sharedLibLdflags := g.getSharedLibFlags(m, ctx)
 
 	linker := tc.GetLinker().GetTool()
@@ -647,7 +706,7 @@ func (g *linuxGenerator) SharedSymlinkActions(ctx blueprint.ModuleContext,
 }
 
 func (g *linuxGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint.ModuleContext) {
-	tc := backend.Get().GetToolchain(m.getTarget())
+	tc := getModuleToolchain(ctx, m.getTarget())
 	objs, implicits := g.CompileObjs(m, ctx, tc)
 
 	installDeps := g.install(m, ctx)
@@ -656,6 +715,7 @@ func (g *linuxGenerator) sharedActions(m *ModuleSharedLibrary, ctx blueprint.Mod
 	g.SharedTocActions(ctx, m)
 
 	installDeps = append(installDeps, file.GetOutputs(m)...)
+	installDeps = append(installDeps, g.packageConfigActions(&m.ModuleLibrary, ctx, m.getLinkName())...)
 	addPhony(m, ctx, installDeps, !isBuiltByDefault(m))
 }
 
@@ -669,7 +729,7 @@ var executableRule = pctx.StaticRule("executable",
 	"shared_libs_flags", "static_libs")
 
 func (g *linuxGenerator) binaryActions(m *ModuleBinary, ctx blueprint.ModuleContext) {
-	tc := backend.Get().GetToolchain(m.Properties.TargetType)
+	tc := getModuleToolchain(ctx, m.Properties.TargetType)
 
 	objectFiles, nonCompiledDeps := g.CompileObjs(m, ctx, tc)
 	/* By default, build all target binaries */
@@ -714,7 +774,7 @@ func (g *linuxGenerator) binaryActions(m *ModuleBinary, ctx blueprint.ModuleCont
 }
 
 func (g *linuxGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blueprint.ModuleContext) {
-	tc := backend.Get().GetToolchain(m.Properties.TargetType)
+	tc := getModuleToolchain(ctx, m.Properties.TargetType)
 
 	objectFiles, nonCompiledDeps := g.CompileObjs(m, ctx, tc)
 	/* By default, build all target binaries */
@@ -755,10 +815,13 @@ func (g *linuxGenerator) strictBinaryActions(m *ModuleStrictBinary, ctx blueprin
 		})
 
 	installDeps := append(g.install(m, ctx), file.GetOutputs(m)...)
+	installDeps = append(installDeps, g.layeringCheckActions(&m.ModuleStrictLibrary, ctx, tc)...)
 	addPhony(m, ctx, installDeps, optional)
 }
 
 func (g *linuxGenerator) executableTestActions(m *ModuleTest, ctx blueprint.ModuleContext) {
-	// Duplicate behaviour of `bob_executable`
+	// Link the test in the same way as `bob_executable`
 	g.strictBinaryActions(&m.ModuleStrictBinary, ctx)
+
+	g.testRunActions(m, ctx)
 }
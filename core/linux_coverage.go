@@ -0,0 +1,116 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// Name of the phony target reporting the line coverage of every module
+// instrumented for coverage, once the tests have run.
+const linuxCoverageReportPhony = "bob_coverage_report"
+
+var _ = pctx.StaticVariable("coverage_report", "${BobScriptsDir}/coverage_report.py")
+
+// Instrumented programs write the coverage data next to the notes, rather
+// than to a file Ninja knows about, so the report of a module depends on the
+// tests having run instead.
+var coverageModuleRule = pctx.StaticRule("coverage_module",
+	blueprint.RuleParams{
+		Command: "$coverage_report module --gcov '$gcov' --name $name --output $out " +
+			"--sources $sources -- $in",
+		CommandDeps: []string{"$coverage_report"},
+		Description: "coverage $name",
+	}, "gcov", "name", "sources")
+
+var coverageSummaryRule = pctx.StaticRule("coverage_summary",
+	blueprint.RuleParams{
+		Command:     "$coverage_report summary --output $out $in",
+		CommandDeps: []string{"$coverage_report"},
+		Description: "$out",
+	})
+
+// The compiler names the notes after the object, without its extension.
+func linuxCoverageNotes(object string) string {
+	return strings.TrimSuffix(object, ".o") + ".gcno"
+}
+
+// The line coverage of a single module, as measured by the tests.
+func linuxCoverageModuleReport(m Compilable) string {
+	return filepath.Join("${BuildDir}", string(m.getTarget()), "coverage", m.outputName()+".json")
+}
+
+func linuxCoverageSummary() string {
+	return filepath.Join("${BuildDir}", "coverage", "summary.json")
+}
+
+// Reports the line coverage of the sources of a module. `notes` and
+// `sources` are in the same order.
+func (g *linuxGenerator) coverageModuleAction(m Compilable, ctx blueprint.ModuleContext,
+	tc toolchain.Toolchain, notes []string, sources []string) {
+
+	gcov, gcovFlags := tc.GetGcov()
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:      coverageModuleRule,
+			Outputs:   []string{linuxCoverageModuleReport(m)},
+			Inputs:    notes,
+			Implicits: []string{linuxTestsPhony},
+			Optional:  true,
+			Args: map[string]string{
+				"gcov":    utils.Join([]string{gcov}, gcovFlags),
+				"name":    m.shortName(),
+				"sources": strings.Join(sources, " "),
+			},
+		})
+}
+
+type linuxCoverageSingleton struct {
+}
+
+func linuxCoverageSingletonFactory() blueprint.Singleton {
+	return &linuxCoverageSingleton{}
+}
+
+// GenerateBuildActions creates the `bob_coverage_report` target, which
+// merges the reports of every instrumented module into a single summary.
+func (s *linuxCoverageSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	if !coverageEnabled(ctx) {
+		return
+	}
+
+	reports := []string{}
+
+	ctx.VisitAllModulesIf(
+		func(m blueprint.Module) bool {
+			e, ok := m.(enableable)
+			return ok && isEnabled(e) && isCoverageInstrumented(ctx, m)
+		},
+		func(m blueprint.Module) {
+			reports = append(reports, linuxCoverageModuleReport(m.(coverable)))
+		})
+
+	sort.Strings(reports)
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     coverageSummaryRule,
+			Inputs:   reports,
+			Outputs:  []string{linuxCoverageSummary()},
+			Optional: true,
+		})
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   []string{linuxCoverageSummary()},
+			Outputs:  []string{linuxCoverageReportPhony},
+			Optional: true,
+		})
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+// Name of the phony target building the install manifests.
+const linuxInstallManifestPhony = "bob_install_manifest"
+
+var _ = pctx.StaticVariable("install_manifest", "${BobScriptsDir}/install_manifest.py")
+
+// The entries are passed in a response file, as an install manifest lists
+// every installed file of the project.
+var installManifestRule = pctx.StaticRule("install_manifest",
+	blueprint.RuleParams{
+		Command:        "$install_manifest --format $format --root $root --entries $out.rsp -o $out",
+		CommandDeps:    []string{"$install_manifest"},
+		Rspfile:        "$out.rsp",
+		RspfileContent: "$entries",
+		Description:    "$out",
+	}, "entries", "format", "root")
+
+// A file installed by a module, together with the attributes of its
+// install group. Empty attributes are read from the installed file when it
+// is listed.
+type installedFile struct {
+	path  string
+	mode  string
+	owner string
+	group string
+	// File recording the path of a file named after the build-id of a
+	// binary, which is only known once the binary is linked. Only used
+	// when path is empty.
+	recordedIn string
+}
+
+// The format read by install_manifest.py and `bob package`. Install paths
+// never contain spaces, as Ninja could not refer to them either. A path
+// read from another file is given as `@file`, which only the install
+// manifest supports.
+func (f installedFile) String() string {
+	path := f.path
+	if path == "" {
+		path = "@" + f.recordedIn
+	}
+	return strings.Join([]string{path, f.mode, f.owner, f.group}, ":")
+}
+
+// The file Ninja knows about, which is the installed file itself unless its
+// path is recorded in another file.
+func (f installedFile) buildFile() string {
+	if f.path == "" {
+		return f.recordedIn
+	}
+	return f.path
+}
+
+var (
+	installedFiles     = map[blueprint.Module][]installedFile{}
+	installedFilesLock sync.Mutex
+)
+
+func installManifestEnabled(ctx configProvider) bool {
+	return getConfig(ctx).Properties.GetBool("install_manifest")
+}
+
+// Records a file installed by the current module, so that it is listed in
+// the install manifest and in packages.
+func recordInstalledFile(ctx blueprint.ModuleContext, props *InstallableProps, path string) {
+	f := installedFile{path: path}
+	if props.InstallGroupMode != nil {
+		f.mode = *props.InstallGroupMode
+	}
+	if props.InstallGroupOwner != nil {
+		f.owner = *props.InstallGroupOwner
+	}
+	if props.InstallGroupGroup != nil {
+		f.group = *props.InstallGroupGroup
+	}
+
+	installedFilesLock.Lock()
+	defer installedFilesLock.Unlock()
+	installedFiles[ctx.Module()] = append(installedFiles[ctx.Module()], f)
+}
+
+// Returns the files installed by a module. Build actions are generated for
+// dependencies first, so this is complete when called from a dependent.
+func getInstalledFiles(m blueprint.Module) []installedFile {
+	installedFilesLock.Lock()
+	defer installedFilesLock.Unlock()
+	return installedFiles[m]
+}
+
+// Sorts installed files by path, for a stable output.
+func sortInstalledFiles(files []installedFile) {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].buildFile() < files[j].buildFile()
+	})
+}
+
+// Returns the debug files installed into install groups with the build-id
+// layout. strip.py records their paths, together with the build-ids.
+func getInstalledDebugFiles(ctx blueprint.SingletonContext) []installedFile {
+	files := []installedFile{}
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		insg, ok := m.(*ModuleInstallGroup)
+		if !ok {
+			return
+		}
+		for _, buildIDFile := range getBuildIDFiles(insg.Name()) {
+			files = append(files, installedFile{
+				owner:      proptools.String(insg.Properties.Owner),
+				group:      proptools.String(insg.Properties.Group),
+				recordedIn: buildIDFile,
+			})
+		}
+	})
+	return files
+}
+
+type linuxInstallManifestSingleton struct {
+}
+
+func linuxInstallManifestSingletonFactory() blueprint.Singleton {
+	return &linuxInstallManifestSingleton{}
+}
+
+// GenerateBuildActions creates the `bob_install_manifest` target, which
+// writes the JSON and mtree manifests of every installed file.
+func (s *linuxInstallManifestSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	if !installManifestEnabled(ctx) {
+		return
+	}
+
+	all := []installedFile{}
+	installedFilesLock.Lock()
+	for _, files := range installedFiles {
+		all = append(all, files...)
+	}
+	installedFilesLock.Unlock()
+	all = append(all, getInstalledDebugFiles(ctx)...)
+
+	// Modules are processed in parallel, so sort for a stable output.
+	sortInstalledFiles(all)
+
+	files := []string{}
+	entries := []string{}
+	for _, f := range all {
+		files = append(files, f.buildFile())
+		entries = append(entries, f.String())
+	}
+
+	manifests := []string{}
+	for _, format := range []string{"json", "mtree"} {
+		manifest := filepath.Join("${BuildDir}", "install_manifest."+format)
+
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:      installManifestRule,
+				Outputs:   []string{manifest},
+				Implicits: files,
+				Optional:  true,
+				Args: map[string]string{
+					"entries": strings.Join(entries, " "),
+					"format":  format,
+					"root":    linuxInstallRoot(ctx),
+				},
+			})
+
+		manifests = append(manifests, manifest)
+	}
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   manifests,
+			Outputs:  []string{linuxInstallManifestPhony},
+			Optional: true,
+		})
+}
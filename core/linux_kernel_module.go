@@ -1,11 +1,15 @@
 package core
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
-	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/file"
+	"github.com/ARM-software/bob-build/internal/fileutils"
+	"github.com/ARM-software/bob-build/internal/utils"
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
 )
 
 var (
@@ -26,13 +30,53 @@ var (
 			Description: "$out",
 		}, "depfile", "extra_includes", "extra_cflags", "kernel_dir", "kernel_cross_compile",
 		"kbuild_options", "make_args", "output_module_dir", "cc_flag", "hostcc_flag", "clang_triple_flag", "ld_flag")
+
+	_            = pctx.StaticVariable("kmod_sign", "${BobScriptsDir}/kmod_sign.py")
+	kmodSignRule = pctx.StaticRule("kmod_sign",
+		blueprint.RuleParams{
+			Command: "python $kmod_sign --kernel $kernel_dir --hash $hash " +
+				"--key $key --cert $cert -o $out $in",
+			CommandDeps: []string{"$kmod_sign"},
+			Description: "$out",
+		}, "cert", "hash", "kernel_dir", "key")
+
+	kmodCompressRule = pctx.StaticRule("kmod_compress",
+		blueprint.RuleParams{
+			Command:     "$compressor -c $in > $out",
+			Description: "$out",
+		}, "compressor")
 )
 
+// Writes the Kbuild file of a module which does not provide its own into
+// the directory Kbuild is run in. The file is written when the build is
+// generated, so it is not an input of the Kbuild step: its content only
+// depends on the sources and name of the module, which are already part of
+// the command. The build is regenerated if the file is changed or removed.
+func (g *linuxGenerator) writeKbuild(ko *ModuleKernelObject, ctx blueprint.ModuleContext, moduleDir string) {
+	content, ok := ko.generateKbuild(ctx)
+	if !ok {
+		return
+	}
+
+	path := filepath.Join(moduleDir, "Kbuild")
+	realPath := expandNinjaString(path)
+	if err := os.MkdirAll(filepath.Dir(realPath), 0755); err != nil {
+		utils.Die("%v", err.Error())
+	}
+
+	var sb strings.Builder
+	sb.WriteString(content)
+	if err := fileutils.WriteIfChanged(realPath, &sb); err != nil {
+		utils.Die("%v", err.Error())
+	}
+	ctx.AddNinjaFileDeps(realPath)
+}
+
 func (g *linuxGenerator) kernelModuleActions(ko *ModuleKernelObject, ctx blueprint.ModuleContext) {
-	outputdir := filepath.Join(backend.Get().KernelModOutputDir(), ko.outputName())
 	optional := !isBuiltByDefault(ko)
 
-	args := ko.generateKbuildArgs(ctx).toDict()
+	kbuildArgs := ko.generateKbuildArgs(ctx)
+	args := kbuildArgs.toDict()
 	delete(args, "kmod_build")
 
 	sources := []string{}
@@ -42,28 +86,72 @@ func (g *linuxGenerator) kernelModuleActions(ko *ModuleKernelObject, ctx bluepri
 			return true
 		})
 
+	// The symbols of extra_symbols modules are passed to Kbuild with the
+	// sources, and are real inputs of the build.
 	sources = append(sources, ko.extraSymbolsFiles(ctx)...)
 
-	ctx.Build(pctx,
-		blueprint.BuildParams{
-			Rule:     kbuildRule,
-			Outputs:  file.GetOutputs(ko),
-			Inputs:   sources,
-			Optional: true,
-			Args:     args,
-		})
+	if !ko.checkSigning(ctx) {
+		return
+	}
+
+	_, implicits := ko.generatedHeaders(ctx)
+	g.writeKbuild(ko, ctx, kbuildArgs.OutputModuleDir)
 
-	// Add a dependency between Module.symvers and the kernel module. This
-	// should really be added to Outputs or ImplicitOutputs above, but
-	// Ninja doesn't support dependency files with multiple outputs yet.
 	ctx.Build(pctx,
 		blueprint.BuildParams{
-			Rule:     blueprint.Phony,
-			Inputs:   file.GetOutputs(ko),
-			Outputs:  []string{filepath.Join(outputdir, "Module.symvers")},
-			Optional: true,
+			Rule:            kbuildRule,
+			Outputs:         []string{ko.builtKo()},
+			ImplicitOutputs: []string{ko.symversFile()},
+			Inputs:          sources,
+			Implicits:       implicits,
+			Optional:        true,
+			Args:            args,
 		})
 
+	// Signing and compression are further steps, so that the installed
+	// module is the final artifact.
+	src := ko.builtKo()
+	if ko.signed() {
+		key := *ko.Properties.Sign_key
+		cert := *ko.Properties.Sign_cert
+		implicits := []string{}
+		for _, p := range []*string{&key, &cert} {
+			if isSignKeyFile(*p) {
+				if !filepath.IsAbs(*p) {
+					*p = getBackendPathInSourceDir(g, *p)
+				}
+				implicits = append(implicits, *p)
+			}
+		}
+
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:      kmodSignRule,
+				Outputs:   []string{ko.signedKo()},
+				Inputs:    []string{src},
+				Implicits: implicits,
+				Optional:  true,
+				Args: map[string]string{
+					"cert":       proptools.ShellEscape(cert),
+					"hash":       proptools.StringDefault(ko.Properties.Sign_hash, "sha256"),
+					"kernel_dir": kbuildArgs.KernelDir,
+					"key":        proptools.ShellEscape(key),
+				},
+			})
+		src = ko.signedKo()
+	}
+
+	if c, ok := kernelModuleCompression[proptools.String(ko.Properties.Compress)]; ok {
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:     kmodCompressRule,
+				Outputs:  file.GetOutputs(ko),
+				Inputs:   []string{src},
+				Optional: true,
+				Args:     map[string]string{"compressor": c.command},
+			})
+	}
+
 	installDeps := append(g.install(ko, ctx), file.GetOutputs(ko)...)
 	addPhony(ko, ctx, installDeps, optional)
 }
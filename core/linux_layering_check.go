@@ -0,0 +1,92 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/file"
+	"github.com/ARM-software/bob-build/core/toolchain"
+)
+
+var _ = pctx.StaticVariable("layering_check", "${BobScriptsDir}/layering_check.py")
+
+// The check preprocesses the source, printing the header each file
+// includes, so it does not rely on the depfile of the compile, which Ninja
+// deletes once it has read it.
+var layeringCheckRule = pctx.StaticRule("layering_check",
+	blueprint.RuleParams{
+		Command: "$layering_check --name $name --deps $deps --hdrs $hdrs --stamp $out -- " +
+			"$compiler $compile_flags $in",
+		CommandDeps: []string{"$layering_check"},
+		Description: "layering check $in",
+	}, "compile_flags", "compiler", "deps", "hdrs", "name")
+
+// Output directory for the results of checking the includes of a module.
+func linuxLayeringCheckDir(m Compilable) string {
+	return filepath.Join("${BuildDir}", string(m.getTarget()), "layering_check", m.outputName())
+}
+
+// Checks that the C and C++ sources of a strict module only include the
+// headers of its direct dependencies. Returns the stamps written once each
+// source passes, which the module's phony target depends on, so that a
+// violation fails the build of the module.
+func (g *linuxGenerator) layeringCheckActions(m *ModuleStrictLibrary, ctx blueprint.ModuleContext,
+	tc toolchain.Toolchain) []string {
+
+	if !layeringCheckEnabled(ctx, m) {
+		return []string{}
+	}
+
+	direct, hdrs := layeringCheckDeps(ctx)
+	if !hasIndirectHeaders(direct, hdrs) {
+		return []string{}
+	}
+
+	owned := []string{}
+	for _, h := range hdrs {
+		owned = append(owned, h.owner+"="+h.path)
+	}
+
+	cc, _ := tc.GetCCompiler()
+	cxx, _ := tc.GetCXXCompiler()
+	orderOnly := GetGeneratedHeadersFiles(ctx)
+
+	stamps := []string{}
+	m.GetFiles(ctx).ForEach(
+		func(source file.Path) bool {
+			var compiler, flags string
+			switch source.Ext() {
+			case ".c":
+				compiler, flags = cc, "$cflags $conlyflags"
+			case ".cc", ".cpp":
+				compiler, flags = cxx, "$cflags $cxxflags"
+			default:
+				return true
+			}
+
+			stamp := filepath.Join(linuxLayeringCheckDir(m), source.RelBuildPath()) + ".stamp"
+
+			ctx.Build(pctx,
+				blueprint.BuildParams{
+					Rule:      layeringCheckRule,
+					Outputs:   []string{stamp},
+					Inputs:    []string{source.BuildPath()},
+					OrderOnly: orderOnly,
+					Optional:  true,
+					Args: map[string]string{
+						"compile_flags": flags,
+						"compiler":      compiler,
+						"deps":          strings.Join(direct, " "),
+						"hdrs":          strings.Join(owned, " "),
+						"name":          m.shortName(),
+					},
+				})
+			stamps = append(stamps, stamp)
+
+			return true
+		})
+
+	return stamps
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/config"
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// The primary builder writes the archives itself, so that packaging does
+// not depend on host tools such as dpkg.
+var _ = pctx.StaticVariable("bob_builder", "${BuildDir}/.bootstrap/bin/bob")
+
+var packageRule = pctx.StaticRule("package",
+	blueprint.RuleParams{
+		Command:        "$bob_builder package --format $format --mtime $mtime --root $root --entries $out.rsp $args -o $out",
+		CommandDeps:    []string{"$bob_builder"},
+		Rspfile:        "$out.rsp",
+		RspfileContent: "$entries",
+		Description:    "$out",
+	}, "args", "entries", "format", "mtime", "root")
+
+// Returns the modification time of the files in archives, in seconds since
+// the epoch. SOURCE_DATE_EPOCH is respected, as described in
+// https://reproducible-builds.org/specs/source-date-epoch/. It is part of
+// the command, so that changing it rebuilds the archives.
+func sourceDateEpoch() string {
+	s := config.GetEnvironmentVariables().SourceDateEpoch
+	if s == "" {
+		return "0"
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		utils.Die("Invalid SOURCE_DATE_EPOCH %s", s)
+	}
+	return s
+}
+
+func linuxPackageDir() string {
+	return filepath.Join("${BuildDir}", "packages")
+}
+
+// Returns the archive of a package in the given format. Debian packages
+// are named as dpkg would name them.
+func linuxPackageFile(m *ModulePackage, format string) string {
+	switch format {
+	case "deb":
+		return filepath.Join(linuxPackageDir(), m.debPackageName()+"_"+
+			proptools.String(m.Properties.Version)+"_"+
+			proptools.String(m.Properties.Deb.Architecture)+".deb")
+	default:
+		return filepath.Join(linuxPackageDir(), m.Name()+".tar.gz")
+	}
+}
+
+func (g *linuxGenerator) packageActions(m *ModulePackage, ctx blueprint.ModuleContext) {
+	files := []installedFile{}
+	ctx.VisitDirectDepsIf(
+		func(dep blueprint.Module) bool { return ctx.OtherModuleDependencyTag(dep) == tag.PackageTag },
+		func(dep blueprint.Module) {
+			if e, ok := dep.(enableable); ok && !isEnabled(e) {
+				return
+			}
+			files = append(files, getInstalledFiles(dep)...)
+		})
+
+	sortInstalledFiles(files)
+
+	paths := []string{}
+	entries := []string{}
+	for _, f := range files {
+		paths = append(paths, f.path)
+		entries = append(entries, f.String())
+	}
+
+	props := &m.Properties.PackageProps
+	debArgs := []string{"--package", m.debPackageName()}
+	for _, field := range []struct {
+		flag  string
+		value *string
+	}{
+		{"--version", props.Version},
+		{"--architecture", props.Deb.Architecture},
+		{"--maintainer", props.Deb.Maintainer},
+		{"--description", props.Deb.Description},
+		{"--section", props.Deb.Section},
+		{"--priority", props.Deb.Priority},
+	} {
+		if field.value != nil {
+			debArgs = append(debArgs, field.flag, proptools.ShellEscape(*field.value))
+		}
+	}
+	for _, dep := range props.Deb.Depends {
+		debArgs = append(debArgs, "--depends", proptools.ShellEscape(dep))
+	}
+
+	outs := []string{}
+	for _, format := range m.formats() {
+		args := []string{}
+		if format == "deb" {
+			args = debArgs
+		}
+
+		out := linuxPackageFile(m, format)
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:      packageRule,
+				Outputs:   []string{out},
+				Implicits: paths,
+				Optional:  true,
+				Args: map[string]string{
+					"args":    strings.Join(args, " "),
+					"entries": strings.Join(entries, " "),
+					"format":  format,
+					"mtime":   sourceDateEpoch(),
+					"root":    linuxInstallRoot(ctx),
+				},
+			})
+		outs = append(outs, out)
+	}
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   outs,
+			Outputs:  []string{m.Name()},
+			Optional: true,
+		})
+}
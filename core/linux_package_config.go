@@ -0,0 +1,145 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/flag"
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+var _ = pctx.StaticVariable("package_config", "${BobScriptsDir}/package_config.py")
+
+var packageConfigRule = pctx.StaticRule("package_config",
+	blueprint.RuleParams{
+		Command:     "$package_config --format $format $args -o $out",
+		CommandDeps: []string{"$package_config"},
+		Description: "$out",
+	}, "args", "format")
+
+// Generates and installs the package files of an installed library. The
+// library is described by its exported flags, and by the libraries its
+// users link with it. Dependencies which generate their own package file in the same
+// format are referred to by package name, others by their link flags.
+// Returns the installed package files.
+func (g *linuxGenerator) packageConfigActions(m *ModuleLibrary, ctx blueprint.ModuleContext, libFile string) []string {
+	props := m.getPackageConfigProps()
+	installPath, ok := m.getInstallableProps().getInstallPath()
+	if !ok {
+		return []string{}
+	}
+
+	name := props.packageName(m.outputName())
+	installRoot := linuxInstallRoot(ctx)
+
+	type packageFile struct {
+		format   string
+		out      string
+		provides func(*PackageConfigProps) bool
+	}
+	files := []packageFile{}
+	if props.pkgConfigEnabled() {
+		files = append(files, packageFile{"pkg-config",
+			filepath.Join(installRoot, props.pkgConfigInstallPath(installPath), name+".pc"),
+			(*PackageConfigProps).pkgConfigEnabled})
+	}
+	if props.cmakeEnabled() {
+		files = append(files, packageFile{"cmake",
+			filepath.Join(installRoot, props.cmakeInstallPath(installPath, name), name+"Config.cmake"),
+			(*PackageConfigProps).cmakeEnabled})
+	}
+	if len(files) == 0 {
+		return []string{}
+	}
+
+	kind := "static"
+	if _, shared := ctx.Module().(*ModuleSharedLibrary); shared {
+		kind = "shared"
+	}
+
+	args := []string{
+		"--name", name,
+		"--version", proptools.ShellEscape(props.packageVersion(m.Properties.Library_version)),
+		"--description", proptools.ShellEscape(props.packageDescription(name)),
+		"--library", filepath.Join(installRoot, installPath, libFile),
+		"--kind", kind,
+	}
+
+	// Flags are split on spaces, so `-isystem <dir>` is passed as two
+	// arguments, which the script joins back.
+	m.FlagsOut().ForEach(func(f flag.Flag) {
+		for _, s := range strings.Fields(f.ToString()) {
+			switch {
+			case f.MatchesType(flag.TypeInclude | flag.TypeCC):
+				args = append(args, "--cflag="+s)
+			case f.MatchesType(flag.TypeLinker):
+				args = append(args, "--ldflag="+s)
+			}
+		}
+	})
+
+	for _, lib := range m.Properties.Ldlibs {
+		args = append(args, "--lib="+lib)
+	}
+
+	outs := []string{}
+	for _, file := range files {
+		depArgs := []string{}
+		// Returns whether the dependency is referred to by its package.
+		addDep := func(dep *ModuleLibrary) bool {
+			depProps := dep.getPackageConfigProps()
+			depPath, installed := dep.getInstallableProps().getInstallPath()
+			if installed && file.provides(depProps) {
+				depArgs = utils.AppendIfUnique(depArgs, "--requires="+depProps.packageName(dep.outputName()))
+				return true
+			}
+			depArgs = utils.AppendIfUnique(depArgs, "--lib="+pathToLibFlag(dep.outputName()))
+			if installed && depPath != installPath {
+				depArgs = utils.AppendIfUnique(depArgs, "--lib-dir="+filepath.Join(installRoot, depPath))
+			}
+			return false
+		}
+
+		// The shared libraries used by the static libraries linked in are
+		// needed too. Users of a static library also link the static
+		// libraries it does not include, unless their package is required.
+		ctx.WalkDeps(func(dep, parent blueprint.Module) bool {
+			switch ctx.OtherModuleDependencyTag(dep) {
+			case tag.SharedTag:
+				if sl, ok := dep.(*ModuleSharedLibrary); ok {
+					addDep(&sl.ModuleLibrary)
+				}
+			case tag.StaticTag:
+				if sl, ok := dep.(*ModuleStaticLibrary); ok {
+					return kind == "shared" || !addDep(&sl.ModuleLibrary)
+				}
+			case tag.WholeStaticTag:
+				return true
+			}
+			return false
+		})
+
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:     packageConfigRule,
+				Outputs:  []string{file.out},
+				Args:     map[string]string{"format": file.format, "args": utils.Join(args, depArgs)},
+				Optional: true,
+			})
+		outs = append(outs, file.out)
+	}
+
+	// The package files are written rather than copied, so they keep the
+	// default mode rather than the one of the install group.
+	manifestProps := *m.getInstallableProps()
+	manifestProps.InstallGroupMode = nil
+	for _, out := range outs {
+		recordInstalledFile(ctx, &manifestProps, out)
+	}
+
+	return outs
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+// Name of the phony target building the symbol indexes.
+const linuxSymbolIndexPhony = "bob_symbol_index"
+
+var _ = pctx.StaticVariable("symbol_index", "${BobScriptsDir}/symbol_index.py")
+
+var symbolIndexRule = pctx.StaticRule("symbol_index",
+	blueprint.RuleParams{
+		Command:        "$symbol_index --root $root --entries $out.rsp -o $out",
+		CommandDeps:    []string{"$symbol_index"},
+		Rspfile:        "$out.rsp",
+		RspfileContent: "$entries",
+		Description:    "$out",
+	}, "entries", "root")
+
+// The binaries whose debug information is installed into each install
+// group, with the file recording their build-id. strip.py names the debug
+// file after the build-id, so it also records the path of the debug file,
+// which the symbol index and the install manifest read.
+var (
+	symbolIndexEntries     = map[string][]string{}
+	symbolIndexEntriesLock sync.Mutex
+)
+
+func recordSymbolIndexEntry(group, installed, buildIDFile string) {
+	symbolIndexEntriesLock.Lock()
+	defer symbolIndexEntriesLock.Unlock()
+	symbolIndexEntries[group] = append(symbolIndexEntries[group], installed+":"+buildIDFile)
+}
+
+// Returns the files recording the build-id and debug file of the binaries
+// whose debug information is installed into an install group.
+func getBuildIDFiles(group string) []string {
+	symbolIndexEntriesLock.Lock()
+	defer symbolIndexEntriesLock.Unlock()
+
+	files := []string{}
+	for _, entry := range symbolIndexEntries[group] {
+		files = append(files, entry[strings.LastIndex(entry, ":")+1:])
+	}
+	return files
+}
+
+type linuxSymbolIndexSingleton struct {
+}
+
+func linuxSymbolIndexSingletonFactory() blueprint.Singleton {
+	return &linuxSymbolIndexSingleton{}
+}
+
+// GenerateBuildActions creates the `bob_symbol_index` target, which writes
+// the index of the build-ids of each install group with `symbol_index` set.
+func (s *linuxSymbolIndexSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	indexes := []string{}
+
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		insg, ok := m.(*ModuleInstallGroup)
+		if !ok || !proptools.Bool(insg.Properties.Symbol_index) {
+			return
+		}
+
+		// Checked by installGroupMutator
+		path := insg.installPath(ctx)
+		if path == nil || *path == "" || !insg.debugBuildID() {
+			return
+		}
+
+		symbolIndexEntriesLock.Lock()
+		entries := append([]string{}, symbolIndexEntries[insg.Name()]...)
+		symbolIndexEntriesLock.Unlock()
+
+		// Modules are processed in parallel, so sort for a stable output.
+		sort.Strings(entries)
+
+		files := []string{}
+		for _, entry := range entries {
+			files = append(files, strings.Split(entry, ":")...)
+		}
+
+		root := linuxInstallRoot(ctx)
+		index := filepath.Join(root, *path, "symbol_index.json")
+
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:      symbolIndexRule,
+				Outputs:   []string{index},
+				Implicits: files,
+				Optional:  true,
+				Args: map[string]string{
+					"entries": strings.Join(entries, " "),
+					"root":    root,
+				},
+			})
+
+		indexes = append(indexes, index)
+	})
+
+	if len(indexes) == 0 {
+		return
+	}
+
+	sort.Strings(indexes)
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   indexes,
+			Outputs:  []string{linuxSymbolIndexPhony},
+			Optional: true,
+		})
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/backend"
+	"github.com/ARM-software/bob-build/core/file"
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/core/toolchain"
+)
+
+// Name of the phony target which runs every enabled `bob_test`.
+const linuxTestsPhony = "bob_tests"
+
+// Number of times a flaky test is attempted before it is considered failed.
+const linuxFlakyTestAttempts = 3
+
+var _ = pctx.StaticVariable("test_runner", "${BobScriptsDir}/run_test.py")
+var testRunRule = pctx.StaticRule("test_run",
+	blueprint.RuleParams{
+		Command: "LD_LIBRARY_PATH=$shared_libs_dir:$$LD_LIBRARY_PATH " +
+			"$test_runner --name $test_name --stamp $out --junit-xml $junit_xml $runner_flags $in $test_args",
+		CommandDeps: []string{"$test_runner"},
+		Description: "test $test_name",
+	}, "junit_xml", "runner_flags", "shared_libs_dir", "test_args", "test_name")
+
+// Output directory for the results of running a test.
+func linuxTestOutputDir(m *ModuleTest) string {
+	return filepath.Join("${BuildDir}", string(m.getTarget()), "tests", m.outputName())
+}
+
+// The base name of the results of a test run. Each shard of a sharded test
+// writes its own results.
+func linuxTestResultName(m *ModuleTest, shard int) string {
+	count := m.TestProperties.shardCount()
+	if count > 1 {
+		return fmt.Sprintf("%s_shard_%d_of_%d", m.outputName(), shard, count)
+	}
+	return m.outputName()
+}
+
+// The stamp file is only up to date once the test has passed, which allows
+// Ninja to skip tests whose inputs have not changed.
+func linuxTestStamp(m *ModuleTest, shard int) string {
+	return filepath.Join(linuxTestOutputDir(m), linuxTestResultName(m, shard)+".stamp")
+}
+
+func linuxTestJunitXML(m *ModuleTest, shard int) string {
+	return filepath.Join(linuxTestOutputDir(m), linuxTestResultName(m, shard)+".xml")
+}
+
+// The stamps of every shard of the test.
+func linuxTestStamps(m *ModuleTest) (stamps []string) {
+	for shard := 0; shard < m.TestProperties.shardCount(); shard++ {
+		stamps = append(stamps, linuxTestStamp(m, shard))
+	}
+	return
+}
+
+// The name of the phony target running a single test.
+func linuxTestRunPhony(m *ModuleTest) string {
+	return "run_" + m.shortName()
+}
+
+// Shared libraries the test loads at runtime. The test needs to run again
+// whenever one of them changes, even if the test binary was not relinked.
+func linuxTestSharedLibs(ctx blueprint.ModuleContext) (libs []string) {
+	ctx.VisitDirectDepsIf(
+		func(dep blueprint.Module) bool { return ctx.OtherModuleDependencyTag(dep) == tag.SharedTag },
+		func(dep blueprint.Module) {
+			if provider, ok := dep.(file.Provider); ok {
+				libs = append(libs, provider.OutFiles().ToStringSliceIf(
+					func(p file.Path) bool { return p.IsType(file.TypeShared) && !p.IsSymLink() },
+					func(p file.Path) string { return p.BuildPath() })...)
+			}
+		})
+	return
+}
+
+// A test can run on the build machine when it is built for the host, or for
+// a target using the same compiler and flags as the host, as in a native
+// build. Tests of a cross-compiled target are only run on request, with
+// their `run_<name>` target.
+func linuxTestRunsOnHost(ctx blueprint.ModuleContext, tgt toolchain.TgtType) bool {
+	if tgt == toolchain.TgtTypeHost {
+		return true
+	}
+	hostCC, hostFlags := backend.Get().GetToolchain(toolchain.TgtTypeHost).GetCCompiler()
+	cc, flags := getModuleToolchain(ctx, tgt).GetCCompiler()
+	return cc == hostCC && strings.Join(flags, " ") == strings.Join(hostFlags, " ")
+}
+
+// Options passed to the test runner, rather than to the test itself, for
+// the given shard.
+func linuxTestRunnerFlags(props *TestProps, shard int) []string {
+	flags := []string{}
+
+	if timeout := props.timeout(); timeout > 0 {
+		flags = append(flags, "--timeout", strconv.Itoa(timeout))
+	}
+
+	if proptools.Bool(props.Flaky) {
+		flags = append(flags, "--attempts", strconv.Itoa(linuxFlakyTestAttempts))
+	}
+
+	if count := props.shardCount(); count > 1 {
+		flags = append(flags, "--shard-index", strconv.Itoa(shard),
+			"--total-shards", strconv.Itoa(count))
+	}
+
+	for _, env := range props.Env {
+		flags = append(flags, "--env", env)
+	}
+
+	return flags
+}
+
+func (g *linuxGenerator) testRunActions(m *ModuleTest, ctx blueprint.ModuleContext) {
+	binary, ok := m.OutFiles().FindSingle(
+		func(p file.Path) bool { return p.IsType(file.TypeBinary) })
+	if !ok {
+		return
+	}
+
+	m.runsOnHost = linuxTestRunsOnHost(ctx, m.getTarget())
+
+	props := &m.TestProperties.TestProps
+	implicits := append(linuxTestSharedLibs(ctx), getBackendPathsInSourceDir(g, props.Data)...)
+
+	for shard := 0; shard < props.shardCount(); shard++ {
+		args := map[string]string{
+			"junit_xml":       linuxTestJunitXML(m, shard),
+			"shared_libs_dir": backend.Get().SharedLibsDir(m.getTarget()),
+			"test_name":       linuxTestResultName(m, shard),
+		}
+
+		if flags := linuxTestRunnerFlags(props, shard); len(flags) > 0 {
+			args["runner_flags"] = strings.Join(flags, " ")
+		}
+
+		if len(props.Args) > 0 {
+			args["test_args"] = strings.Join(props.Args, " ")
+		}
+
+		ctx.Build(pctx,
+			blueprint.BuildParams{
+				Rule:            testRunRule,
+				Outputs:         []string{linuxTestStamp(m, shard)},
+				ImplicitOutputs: []string{linuxTestJunitXML(m, shard)},
+				Inputs:          []string{binary.BuildPath()},
+				Implicits:       implicits,
+				Optional:        true,
+				Args:            args,
+			})
+	}
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   linuxTestStamps(m),
+			Outputs:  []string{linuxTestRunPhony(m)},
+			Optional: true,
+		})
+}
+
+type linuxTestsSingleton struct {
+}
+
+func linuxTestsSingletonFactory() blueprint.Singleton {
+	return &linuxTestsSingleton{}
+}
+
+// GenerateBuildActions creates the `bob_tests` phony target, depending on
+// the result stamp of every enabled test which can run on the build machine.
+func (s *linuxTestsSingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	stamps := []string{}
+
+	ctx.VisitAllModulesIf(
+		func(m blueprint.Module) bool {
+			t, ok := m.(*ModuleTest)
+			return ok && isEnabled(t) && t.runsOnHost
+		},
+		func(m blueprint.Module) {
+			stamps = append(stamps, linuxTestStamps(m.(*ModuleTest))...)
+		})
+
+	sort.Strings(stamps)
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   stamps,
+			Outputs:  []string{linuxTestsPhony},
+			Optional: true,
+		})
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/core/backend"
+	"github.com/ARM-software/bob-build/core/file"
+)
+
+// Name of the phony target which runs clang-tidy on every module with
+// `tidy` enabled.
+const linuxTidyPhony = "bob_tidy"
+
+// clang-tidy only writes the fixes file when there is something to fix, so
+// create it regardless, to keep Ninja from rerunning the analysis.
+var tidyRule = pctx.StaticRule("tidy",
+	blueprint.RuleParams{
+		Command: "rm -f $fixes && $tidy $tidy_flags --quiet --export-fixes=$fixes $in -- $compile_flags && " +
+			"touch $fixes $out",
+		Description: "tidy $in",
+	}, "compile_flags", "fixes", "tidy", "tidy_flags")
+
+// Output directory for the results of running clang-tidy on a module.
+func linuxTidyDir(m Compilable) string {
+	return filepath.Join("${BuildDir}", string(m.getTarget()), "tidy", m.outputName())
+}
+
+// The name of the phony target running clang-tidy on a single module.
+func linuxTidyPhonyName(m phonyInterface) string {
+	return "tidy_" + m.shortName()
+}
+
+// Runs clang-tidy on a single source, using the same flags as it is
+// compiled with. Returns the stamp written once the analysis passes.
+func (g *linuxGenerator) tidyAction(m tidyable, ctx blueprint.ModuleContext,
+	source file.Path, compileFlags string, orderOnly []string) string {
+
+	props := m.getTidyProps()
+	base := filepath.Join(linuxTidyDir(m), source.RelBuildPath())
+	stamp := base + ".tidy"
+	fixes := base + ".yaml"
+
+	// Check lists contain globs, so protect them from the shell.
+	flags := []string{}
+	for _, f := range props.tidyCheckFlags() {
+		flags = append(flags, backend.Get().EscapeFlag(f))
+	}
+	flags = append(flags, props.Tidy_flags...)
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:            tidyRule,
+			Outputs:         []string{stamp},
+			ImplicitOutputs: []string{fixes},
+			Inputs:          []string{source.BuildPath()},
+			OrderOnly:       orderOnly,
+			Optional:        true,
+			Args: map[string]string{
+				"compile_flags": compileFlags,
+				"fixes":         fixes,
+				"tidy":          getConfig(ctx).Properties.GetString("clang_tidy_binary"),
+				"tidy_flags":    strings.Join(flags, " "),
+			},
+		})
+
+	return stamp
+}
+
+// Creates the phony target running clang-tidy on all the sources of a module.
+func (g *linuxGenerator) tidyPhony(m tidyable, ctx blueprint.ModuleContext, stamps []string) {
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   stamps,
+			Outputs:  []string{linuxTidyPhonyName(m)},
+			Optional: true,
+		})
+}
+
+type linuxTidySingleton struct {
+}
+
+func linuxTidySingletonFactory() blueprint.Singleton {
+	return &linuxTidySingleton{}
+}
+
+// GenerateBuildActions creates the `bob_tidy` phony target, depending on the
+// per-module clang-tidy targets.
+func (s *linuxTidySingleton) GenerateBuildActions(ctx blueprint.SingletonContext) {
+	targets := []string{}
+
+	ctx.VisitAllModulesIf(
+		func(m blueprint.Module) bool {
+			t, ok := m.(tidyable)
+			if !ok || !t.getTidyProps().tidyEnabled() {
+				return false
+			}
+			e, ok := m.(enableable)
+			return ok && isEnabled(e)
+		},
+		func(m blueprint.Module) {
+			targets = append(targets, linuxTidyPhonyName(m.(tidyable)))
+		})
+
+	if len(targets) == 0 {
+		return
+	}
+
+	sort.Strings(targets)
+
+	ctx.Build(pctx,
+		blueprint.BuildParams{
+			Rule:     blueprint.Phony,
+			Inputs:   targets,
+			Outputs:  []string{linuxTidyPhony},
+			Optional: true,
+		})
+}
@@ -1,9 +1,110 @@
 package core
 
-import "github.com/google/blueprint"
+import (
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// Default timeouts, in seconds, for each test size.
+var testSizeTimeouts = map[string]int{
+	"small":    60,
+	"medium":   300,
+	"large":    900,
+	"enormous": 3600,
+}
+
+// TestProps describe how a test is run.
+type TestProps struct {
+	// Arguments passed to the test binary.
+	Args []string
+	// Environment variables set while running the test, in `NAME=value` form.
+	Env []string
+	// Number of seconds after which the test is killed. Overrides the
+	// timeout implied by `size`.
+	Timeout *int
+	// Number of shards the test is split into. Each shard is run separately.
+	Shard_count *int
+	// Size of the test, one of `small`, `medium`, `large` or `enormous`.
+	Size *string
+	// Files needed by the test at runtime.
+	Data []string
+	// Whether the test is flaky, in which case it is retried on failure.
+	Flaky *bool
+}
+
+func (p *TestProps) validate(ctx blueprint.BaseModuleContext) {
+	if p.Size != nil {
+		if _, ok := testSizeTimeouts[*p.Size]; !ok {
+			propertyErrorf(ctx, "size", "must be one of small, medium, large or enormous, not '%s'", *p.Size)
+		}
+	}
+
+	if p.Timeout != nil && *p.Timeout <= 0 {
+		propertyErrorf(ctx, "timeout", "must be a positive number of seconds")
+	}
+
+	if p.Shard_count != nil && *p.Shard_count <= 0 {
+		propertyErrorf(ctx, "shard_count", "must be at least 1")
+	}
+
+	for _, env := range p.Env {
+		if !strings.Contains(env, "=") {
+			propertyErrorf(ctx, "env", "'%s' is not of the form NAME=value", env)
+		}
+	}
+}
+
+// timeout returns the timeout of the test in seconds, or 0 if the test
+// should not be timed out.
+func (p *TestProps) timeout() int {
+	if p.Timeout != nil {
+		return *p.Timeout
+	}
+	if p.Size != nil {
+		return testSizeTimeouts[*p.Size]
+	}
+	return 0
+}
+
+func (p *TestProps) shardCount() int {
+	if p.Shard_count != nil {
+		return *p.Shard_count
+	}
+	return 1
+}
 
 type ModuleTest struct {
 	ModuleStrictBinary
+	TestProperties struct {
+		TestProps
+	}
+
+	// Whether the test binary can run on the build machine, set by the
+	// Linux generator
+	runsOnHost bool
+}
+
+func (m *ModuleTest) processPaths(ctx blueprint.BaseModuleContext) {
+	m.ModuleStrictBinary.processPaths(ctx)
+	m.TestProperties.Data = utils.PrefixDirs(m.TestProperties.Data, projectModuleDir(ctx))
+	m.TestProperties.validate(ctx)
+}
+
+func (m *ModuleTest) getEscapeProperties() []*[]string {
+	return append(m.ModuleStrictBinary.getEscapeProperties(),
+		&m.TestProperties.Args,
+		&m.TestProperties.Env)
+}
+
+func (m *ModuleTest) FeaturableProperties() []interface{} {
+	return append(m.ModuleStrictBinary.FeaturableProperties(), &m.TestProperties.TestProps)
+}
+
+func (m *ModuleTest) targetableProperties() []interface{} {
+	return append(m.ModuleStrictBinary.targetableProperties(), &m.TestProperties.TestProps)
 }
 
 func (m *ModuleTest) GenerateBuildActions(ctx blueprint.ModuleContext) {
@@ -17,9 +118,10 @@ func executableTestFactory(config *BobConfig) (blueprint.Module, []interface{})
 
 	module := &ModuleTest{}
 	module.Properties.Linkstatic = &t // always true for executables
-	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, SplittableProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{})
-	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{})
-	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{})
-	return module, []interface{}{&module.Properties,
+	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, SplittableProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TestProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TestProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TestProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TestProps{}, TidyProps{}, CoverageProps{})
+	return module, []interface{}{&module.Properties, &module.TestProperties,
 		&module.SimpleName.Properties}
 }
@@ -1,11 +1,15 @@
 package core
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/flag"
 	"github.com/ARM-software/bob-build/core/module"
+	"github.com/ARM-software/bob-build/core/tag"
 	"github.com/ARM-software/bob-build/core/toolchain"
 	"github.com/ARM-software/bob-build/core/toolchain/mapper"
 	"github.com/google/blueprint"
@@ -31,7 +35,18 @@ type ModuleToolchainProps struct {
 	// Wrapper for all build commands (object file compilation *and* linking)
 	Build_wrapper *string
 
+	// Tools replacing those of the toolchain configured for each variant.
+	// Modules using this `bob_toolchain` compile and link with them.
+	Cc      *string
+	Cxx     *string
+	Ar      *string
+	Ld      *string
+	Nm      *string
+	Ranlib  *string
+	Sysroot *string
+
 	AndroidMTEProps
+	SanitizeProps
 
 	Hwasan_enabled *bool
 }
@@ -58,13 +73,22 @@ type ModuleToolchain struct {
 		Target     TargetSpecific
 		Host       TargetSpecific
 		TargetType toolchain.TgtType `blueprint:"mutated"`
+		VariantSpecific
 
 		Features
 	}
+
+	// The directory of the module in the source tree
+	sourceDir string
+
+	// The toolchain using the tools above, created on first use
+	toolchain     toolchain.Toolchain
+	toolchainOnce sync.Once
 }
 
 type BackendConfiguration interface {
 	stripable
+	sanitizable
 	GetBuildWrapperAndDeps(blueprint.ModuleContext) (string, []string)
 	GetMteProps(blueprint.ModuleContext) AndroidMTEProps
 	IsHwAsanEnabled() bool
@@ -111,7 +135,7 @@ func (m *ModuleToolchain) GenerateBuildActions(ctx blueprint.ModuleContext) {
 }
 
 func (m *ModuleToolchain) supportedVariants() []toolchain.TgtType {
-	return []toolchain.TgtType{toolchain.TgtTypeHost, toolchain.TgtTypeTarget}
+	return append([]toolchain.TgtType{toolchain.TgtTypeHost}, backend.Get().TargetVariants()...)
 }
 
 func (m *ModuleToolchain) disable() {
@@ -135,6 +159,8 @@ func (m *ModuleToolchain) getTargetSpecific(tgt toolchain.TgtType) *TargetSpecif
 		return &m.Properties.Host
 	} else if tgt == toolchain.TgtTypeTarget {
 		return &m.Properties.Target
+	} else if variant := m.Properties.VariantSpecific.get(tgt); variant != nil {
+		return variant
 	}
 
 	return nil
@@ -154,6 +180,10 @@ func (m *ModuleToolchain) GetMteProps(blueprint.ModuleContext) AndroidMTEProps {
 	return m.Properties.AndroidMTEProps
 }
 
+func (m *ModuleToolchain) getSanitizeProps() *SanitizeProps {
+	return &m.Properties.SanitizeProps
+}
+
 func (m *ModuleToolchain) GetBuildWrapperAndDeps(ctx blueprint.ModuleContext) (string, []string) {
 	// Copies the behaviour from core/build.go
 	if m.Properties.Build_wrapper != nil {
@@ -171,6 +201,54 @@ func (m *ModuleToolchain) GetBuildWrapperAndDeps(ctx blueprint.ModuleContext) (s
 	return "", []string{}
 }
 
+// toolSet returns the tools set by the module.
+func (m *ModuleToolchain) toolSet() toolchain.ToolSet {
+	props := &m.Properties.ModuleToolchainProps
+	return toolchain.ToolSet{
+		Cc:      proptools.String(props.Cc),
+		Cxx:     proptools.String(props.Cxx),
+		Ar:      proptools.String(props.Ar),
+		Ld:      proptools.String(props.Ld),
+		Nm:      proptools.String(props.Nm),
+		Ranlib:  proptools.String(props.Ranlib),
+		Sysroot: proptools.String(props.Sysroot),
+		Dir:     m.sourceDir,
+	}
+}
+
+// getToolchain returns the toolchain of the module's variant, using the
+// tools set by the module.
+func (m *ModuleToolchain) getToolchain() toolchain.Toolchain {
+	m.toolchainOnce.Do(func() {
+		m.toolchain = backend.Get().GetToolchain(m.Properties.TargetType)
+		if tools := m.toolSet(); !tools.IsEmpty() {
+			m.toolchain = toolchain.NewToolchainFromToolSet(tools, m.toolchain)
+		}
+	})
+	return m.toolchain
+}
+
+// getModuleToolchain returns the toolchain a module compiles with. This is
+// the one of the `bob_toolchain` it uses, which the `toolchain` property or
+// ToolchainModuleMap selects, or the toolchain configured for its variant.
+// Only the Linux builder compiles with the tools of a `bob_toolchain`.
+func getModuleToolchain(ctx blueprint.BaseModuleContext, tgt toolchain.TgtType) toolchain.Toolchain {
+	tc := backend.Get().GetToolchain(tgt)
+	if _, ok := getGenerator(ctx).(*linuxGenerator); !ok {
+		return tc
+	}
+	ctx.VisitDirectDepsIf(
+		func(dep blueprint.Module) bool {
+			return ctx.OtherModuleDependencyTag(dep) == tag.ToolchainTag
+		},
+		func(dep blueprint.Module) {
+			if t, ok := dep.(*ModuleToolchain); ok {
+				tc = t.getToolchain()
+			}
+		})
+	return tc
+}
+
 func (m *ModuleToolchain) IsHwAsanEnabled() bool {
 	return proptools.Bool(m.Properties.Hwasan_enabled)
 }
@@ -219,6 +297,14 @@ func (m *ModuleToolchain) setDebugPath(path *string) {
 	m.Properties.setDebugPath(path)
 }
 
+func (m *ModuleToolchain) getDebugInfoLayout() (bool, bool) {
+	return m.Properties.getDebugInfoLayout()
+}
+
+func (m *ModuleToolchain) setDebugInfoLayout(buildID bool, compress bool) {
+	m.Properties.setDebugInfoLayout(buildID, compress)
+}
+
 func (m *ModuleToolchain) stripOutputDir(g generatorBackend) string {
 	return getBackendPathInBuildDir(g, string(m.Properties.TargetType), "strip")
 }
@@ -244,6 +330,8 @@ func (m *ModuleToolchain) GetTags() []string {
 }
 
 func (m *ModuleToolchain) processPaths(ctx blueprint.BaseModuleContext) {
+	m.sourceDir = filepath.Join(getSourceDir(), projectModuleDir(ctx))
+
 	if m.Properties.Build_wrapper != nil {
 		// Copies core/build_props.go to duplicate the behaviour for `build_wrapper`
 		*m.Properties.Build_wrapper = strings.TrimSpace(*m.Properties.Build_wrapper)
@@ -262,6 +350,7 @@ func ModuleToolchainFactory(config *BobConfig) (blueprint.Module, []interface{})
 	module.Properties.Features.Init(&config.Properties, ModuleToolchainProps{}, StripProps{}, TagableProps{})
 	module.Properties.Host.init(&config.Properties, ModuleToolchainProps{}, StripProps{}, TagableProps{})
 	module.Properties.Target.init(&config.Properties, ModuleToolchainProps{}, StripProps{}, TagableProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, ModuleToolchainProps{}, StripProps{}, TagableProps{})
 
 	return module, []interface{}{&module.Properties,
 		&module.SimpleName.Properties}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/ARM-software/bob-build/core/module"
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/internal/utils"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+)
+
+// PackageProps describes the properties of the bob_package module
+type PackageProps struct {
+	// Install groups whose installed files are packaged
+	Install_groups []string
+	// Archive formats to produce, `tar` and/or `deb`. Defaults to `tar`.
+	Formats []string
+	// Version of the package
+	Version *string
+
+	// Control fields of the `.deb` package
+	Deb struct {
+		// Name of the package. Defaults to the module name.
+		Package *string
+		// Debian architecture of the package, e.g. `arm64` or `all`
+		Architecture *string
+		Maintainer   *string
+		Description  *string
+		Section      *string
+		Priority     *string
+		// Packages this package depends on, e.g. `libc6 (>= 2.31)`
+		Depends []string
+	}
+}
+
+// Type representing each bob_package module
+type ModulePackage struct {
+	module.ModuleBase
+	Properties struct {
+		PackageProps
+		Features
+	}
+}
+
+func (m *ModulePackage) Features() *Features {
+	return &m.Properties.Features
+}
+
+func (m *ModulePackage) FeaturableProperties() []interface{} {
+	return []interface{}{&m.Properties.PackageProps}
+}
+
+// Called by Blueprint to generate the rules associated with the package.
+// This is forwarded to the backend to handle.
+func (m *ModulePackage) GenerateBuildActions(ctx blueprint.ModuleContext) {
+	getGenerator(ctx).packageActions(m, ctx)
+}
+
+func (m ModulePackage) GetProperties() interface{} {
+	return m.Properties
+}
+
+func (m *ModulePackage) formats() []string {
+	if len(m.Properties.Formats) == 0 {
+		return []string{"tar"}
+	}
+	return m.Properties.Formats
+}
+
+func (m *ModulePackage) debPackageName() string {
+	return proptools.StringDefault(m.Properties.Deb.Package, m.Name())
+}
+
+// Create the structure representing the bob_package
+func packageFactory(config *BobConfig) (blueprint.Module, []interface{}) {
+	module := &ModulePackage{}
+	module.Properties.Features.Init(&config.Properties, PackageProps{})
+	return module, []interface{}{&module.Properties, &module.SimpleName.Properties}
+}
+
+// The packages which include each install group
+var (
+	packagedGroups     = map[string][]string{}
+	packagedGroupsLock sync.Mutex
+)
+
+// Checks the properties of each package, and records the install groups
+// it includes.
+func packageGroupsMutator(ctx blueprint.BottomUpMutatorContext) {
+	m, ok := ctx.Module().(*ModulePackage)
+	if !ok {
+		return
+	}
+
+	valid := true
+	if len(m.Properties.Install_groups) == 0 {
+		propertyErrorf(ctx, "install_groups", "must list at least one install group")
+		valid = false
+	}
+	seen := map[string]bool{}
+	for _, format := range m.formats() {
+		if format != "tar" && format != "deb" {
+			propertyErrorf(ctx, "formats", "unsupported format %s, expected tar or deb", format)
+			valid = false
+		}
+		if seen[format] {
+			propertyErrorf(ctx, "formats", "lists format %s more than once", format)
+			valid = false
+		}
+		seen[format] = true
+		if format == "deb" {
+			if m.Properties.Version == nil || m.Properties.Deb.Architecture == nil ||
+				m.Properties.Deb.Maintainer == nil || m.Properties.Deb.Description == nil {
+				propertyErrorf(ctx, "deb", "must set version, deb.architecture, deb.maintainer "+
+					"and deb.description to produce a deb")
+				valid = false
+			}
+		}
+	}
+	if !valid {
+		return
+	}
+
+	ctx.AddDependency(ctx.Module(), tag.InstallGroupTag, m.Properties.Install_groups...)
+
+	packagedGroupsLock.Lock()
+	defer packagedGroupsLock.Unlock()
+	for _, group := range m.Properties.Install_groups {
+		packagedGroups[group] = utils.AppendIfUnique(packagedGroups[group], m.Name())
+	}
+}
+
+// Adds modules installed into a packaged install group as dependencies of
+// the package, so that their installed files are known when the package is
+// generated.
+func packageMutator(ctx blueprint.BottomUpMutatorContext) {
+	ins, ok := ctx.Module().(installable)
+	if !ok {
+		return
+	}
+
+	group := ins.getInstallableProps().Install_group
+	if group == nil {
+		return
+	}
+
+	packagedGroupsLock.Lock()
+	packages := packagedGroups[*group]
+	packagedGroupsLock.Unlock()
+
+	for _, pkg := range packages {
+		ctx.AddReverseDependency(ctx.Module(), tag.PackageTag, pkg)
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// PackageConfigProps describe the package files generated for an
+// installed library, so that other projects can find it with pkg-config
+// or with CMake's `find_package()`.
+type PackageConfigProps struct {
+	Package_config struct {
+		// Generate a pkg-config `<name>.pc` file.
+		Pkg_config *bool
+		// Generate a CMake `<name>Config.cmake` file.
+		Cmake *bool
+		// Name of the package. Defaults to the name of the library,
+		// without its `lib` prefix.
+		Name *string
+		// Version of the package. Defaults to `library_version`.
+		Version *string
+		// Description of the package, used by pkg-config.
+		Description *string
+		// Install path of the pkg-config file. Defaults to `pkgconfig`
+		// in the install path of the library.
+		Pkg_config_install_path *string
+		// Install path of the CMake file. Defaults to `cmake/<name>`
+		// in the install path of the library.
+		Cmake_install_path *string
+	}
+}
+
+func (p *PackageConfigProps) pkgConfigEnabled() bool {
+	return proptools.Bool(p.Package_config.Pkg_config)
+}
+
+func (p *PackageConfigProps) cmakeEnabled() bool {
+	return proptools.Bool(p.Package_config.Cmake)
+}
+
+func (p *PackageConfigProps) isSet() bool {
+	return p.Package_config.Pkg_config != nil || p.Package_config.Cmake != nil
+}
+
+// Returns the name of the package of a library. The `lib` prefix is
+// dropped, as pkg-config and CMake packages are usually named without it.
+func (p *PackageConfigProps) packageName(libName string) string {
+	if p.Package_config.Name != nil {
+		return *p.Package_config.Name
+	}
+	return strings.TrimPrefix(libName, "lib")
+}
+
+func (p *PackageConfigProps) packageVersion(libraryVersion string) string {
+	if p.Package_config.Version != nil {
+		return *p.Package_config.Version
+	}
+	if libraryVersion != "" {
+		return libraryVersion
+	}
+	// pkg-config requires a version
+	return "0"
+}
+
+func (p *PackageConfigProps) packageDescription(name string) string {
+	return proptools.StringDefault(p.Package_config.Description, name)
+}
+
+// Returns the install paths of the package files, relative to the install
+// root, given the install path of the library.
+func (p *PackageConfigProps) pkgConfigInstallPath(libInstallPath string) string {
+	return proptools.StringDefault(p.Package_config.Pkg_config_install_path,
+		filepath.Join(libInstallPath, "pkgconfig"))
+}
+
+func (p *PackageConfigProps) cmakeInstallPath(libInstallPath, name string) string {
+	return proptools.StringDefault(p.Package_config.Cmake_install_path,
+		filepath.Join(libInstallPath, "cmake", name))
+}
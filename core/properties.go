@@ -8,7 +8,6 @@ import (
 	"github.com/google/blueprint/proptools"
 
 	"github.com/ARM-software/bob-build/core/tag"
-	"github.com/ARM-software/bob-build/core/toolchain"
 	"github.com/ARM-software/bob-build/internal/utils"
 )
 
@@ -221,17 +220,11 @@ func featureApplierMutator(ctx blueprint.TopDownMutatorContext) {
 		// Apply features in target-specific properties.
 		// This should happen for all modules which support host:{} and target:{}
 		if ts, ok := module.(targetSpecificLibrary); ok {
-			host := ts.getTargetSpecific(toolchain.TgtTypeHost)
-			target := ts.getTargetSpecific(toolchain.TgtTypeTarget)
-
-			var tgtprops = []propmap{
-				{[]interface{}{host.getTargetSpecificProps()}, &host.Features},
-				{[]interface{}{target.getTargetSpecificProps()}, &target.Features},
+			for _, tgt := range specificTargets() {
+				specific := ts.getTargetSpecific(tgt)
+				props = append(props, propmap{[]interface{}{specific.getTargetSpecificProps()}, &specific.Features})
+				templProps = append(templProps, specific.getTargetSpecificProps())
 			}
-			props = append(props, tgtprops...)
-
-			templProps = append(templProps, host.getTargetSpecificProps())
-			templProps = append(templProps, target.getTargetSpecificProps())
 		}
 
 		for _, prop := range props {
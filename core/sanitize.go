@@ -0,0 +1,124 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"github.com/ARM-software/bob-build/core/tag"
+	"github.com/ARM-software/bob-build/core/toolchain"
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// SanitizeProps enable the compiler's runtime sanitizers. They match the
+// `sanitize` property of Soong.
+type SanitizeProps struct {
+	Sanitize struct {
+		// Enable AddressSanitizer.
+		Address *bool
+		// Enable UndefinedBehaviorSanitizer with its default set of checks.
+		Undefined *bool
+		// Enable ThreadSanitizer.
+		Thread *bool
+		// Enable MemorySanitizer. Only supported by Clang.
+		Memory *bool
+		// Additional UndefinedBehaviorSanitizer checks, e.g. `integer`.
+		Misc_undefined []string
+	}
+}
+
+// Modules implementing sanitizable can enable sanitizers. Strict modules
+// take their sanitizers from their `bob_toolchain`.
+type sanitizable interface {
+	getSanitizeProps() *SanitizeProps
+}
+
+// Sanitizers which use their own runtime, and so cannot be combined with
+// each other.
+var exclusiveSanitizers = []string{"address", "thread", "memory"}
+
+// Returns the enabled sanitizers, named as expected by `-fsanitize=`.
+func (p *SanitizeProps) sanitizers() (list []string) {
+	s := &p.Sanitize
+	if proptools.Bool(s.Address) {
+		list = append(list, "address")
+	}
+	if proptools.Bool(s.Undefined) {
+		list = append(list, "undefined")
+	}
+	if proptools.Bool(s.Thread) {
+		list = append(list, "thread")
+	}
+	if proptools.Bool(s.Memory) {
+		list = append(list, "memory")
+	}
+	return utils.AppendUnique(list, s.Misc_undefined)
+}
+
+func checkSanitizerConflicts(ctx blueprint.ModuleContext, sanitizers []string) {
+	conflicting := []string{}
+	for _, s := range exclusiveSanitizers {
+		if utils.Contains(sanitizers, s) {
+			conflicting = append(conflicting, s)
+		}
+	}
+	if len(conflicting) > 1 {
+		propertyErrorf(ctx, "sanitize", "sanitizers %s cannot be used together", strings.Join(conflicting, ", "))
+	}
+}
+
+// Returns the sanitizers enabled on the module itself.
+func moduleSanitizers(bc BackendConfiguration) []string {
+	if bc == nil {
+		return []string{}
+	}
+	return bc.getSanitizeProps().sanitizers()
+}
+
+// Returns the flags needed to compile a module with its sanitizers,
+// checking that the compiler supports them.
+func sanitizerCompileFlags(ctx blueprint.ModuleContext, tc toolchain.Toolchain, bc BackendConfiguration) []string {
+	sanitizers := moduleSanitizers(bc)
+	if len(sanitizers) == 0 {
+		return []string{}
+	}
+	checkSanitizerConflicts(ctx, sanitizers)
+
+	cflags, _ := tc.GetSanitizerFlags(sanitizers)
+	if len(cflags) == 0 {
+		propertyErrorf(ctx, "sanitize", "the %s toolchain does not support sanitizers", bc.getTarget())
+	}
+	for _, f := range cflags {
+		if !tc.CheckFlagIsSupported("c", f) {
+			propertyErrorf(ctx, "sanitize", "%s is not supported by the %s compiler", f, bc.getTarget())
+		}
+	}
+	return cflags
+}
+
+// Returns the flags needed to link a module. The sanitizer runtimes are only
+// linked into executables and shared libraries, so these include the
+// sanitizers of every library linked in, directly or not.
+func sanitizerLinkFlags(ctx blueprint.ModuleContext, tc toolchain.Toolchain, bc BackendConfiguration) []string {
+	own := moduleSanitizers(bc)
+	sanitizers := append([]string{}, own...)
+
+	ctx.WalkDeps(func(dep, parent blueprint.Module) bool {
+		switch ctx.OtherModuleDependencyTag(dep) {
+		case tag.StaticTag, tag.WholeStaticTag, tag.SharedTag, tag.ToolchainTag:
+			if s, ok := dep.(sanitizable); ok {
+				sanitizers = utils.AppendUnique(sanitizers, s.getSanitizeProps().sanitizers())
+			}
+			return true
+		}
+		return false
+	})
+
+	if len(sanitizers) > len(own) {
+		checkSanitizerConflicts(ctx, sanitizers)
+	}
+
+	_, ldflags := tc.GetSanitizerFlags(sanitizers)
+	return ldflags
+}
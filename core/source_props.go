@@ -52,7 +52,7 @@ func (s *SourceProps) processPaths(ctx blueprint.BaseModuleContext) {
 
 	for _, src := range s.Srcs {
 		if strings.HasPrefix(filepath.Clean(src), "../") {
-			GetLogger().Warn(warnings.RelativeUpLinkWarning, ctx.BlueprintsFile(), ctx.ModuleName())
+			warnModule(ctx, warnings.RelativeUpLinkWarning)
 		}
 	}
 
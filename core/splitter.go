@@ -6,6 +6,7 @@ import (
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/proptools"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/tag"
 	"github.com/ARM-software/bob-build/core/toolchain"
 	"github.com/ARM-software/bob-build/internal/utils"
@@ -97,6 +98,29 @@ func supportedVariantsMutator(ctx blueprint.BottomUpMutatorContext) {
 	}
 }
 
+// isVariant returns whether a target type names a variant, i.e. `host`,
+// `target`, or one of the variants listed in `target_variants`.
+func isVariant(tgt toolchain.TgtType) bool {
+	if tgt == toolchain.TgtTypeHost || tgt == toolchain.TgtTypeTarget {
+		return true
+	}
+	for _, variant := range backend.Get().TargetVariants() {
+		if tgt == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// targetVariant maps `target` to the first target variant, so that modules
+// and dependencies referring to `target` keep working in multilib builds.
+func targetVariant(tgt toolchain.TgtType) toolchain.TgtType {
+	if tgt == toolchain.TgtTypeTarget {
+		return backend.Get().TargetVariants()[0]
+	}
+	return tgt
+}
+
 func tgtToString(tgts []toolchain.TgtType) []string {
 	variants := make([]string, len(tgts))
 	for i, v := range tgts {
@@ -6,6 +6,7 @@
 package core
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -62,6 +63,31 @@ func GetLogger() *warnings.WarningLogger {
 	return logger
 }
 
+// WarningCategoryInfo describes a warning category added by a project.
+type WarningCategoryInfo = warnings.CategoryInfo
+
+// RegisterWarningCategory makes a project's own warning category available
+// to the logger and to the filters in `BOB_LOG_WARNINGS`. The filters are
+// parsed when Main or Lint sets up the logger, so categories must be
+// registered before either is called, e.g. in the `main` of a wrapper of
+// the primary builder.
+func RegisterWarningCategory(info WarningCategoryInfo) error {
+	if logger != nil {
+		return fmt.Errorf("Warning category '%s' registered after the logger was set up", info.Name)
+	}
+	return warnings.RegisterCategory(info)
+}
+
+// warnModule raises a warning about the module, passing its tags so that
+// filters scoped to them apply.
+func warnModule(ctx blueprint.BaseModuleContext, category warnings.Category, args ...interface{}) {
+	var tags []string
+	if t, ok := ctx.Module().(Tagable); ok {
+		tags = t.GetTags()
+	}
+	logger.WarnTagged(category, ctx.BlueprintsFile(), ctx.ModuleName(), tags, args...)
+}
+
 func SetupLogger(env *config.EnvironmentVariables) {
 	if env == nil {
 		// ioutil.Discard has been deprecated since 1.16, but Bob supports as old as 1.11, move to io.Discard
@@ -72,14 +98,26 @@ func SetupLogger(env *config.EnvironmentVariables) {
 		if err != nil {
 			utils.Die("Failed to setup logger, error opening '%s' file: %v", env.LogWarningsFile, err)
 		}
+		format, err := warnings.ParseFormat(env.LogWarningsFormat)
+		if err != nil {
+			utils.Die("Failed to setup logger: %v", err)
+		}
 		loggerFile = f
-		logger = warnings.New(loggerFile, env.LogWarnings)
+		logger = warnings.NewWithFormat(loggerFile, format, env.LogWarnings)
+		logger.SetLocator(bpPosition)
+		logger.SetSourceRoot(env.SrcDir)
 	}
 }
 
 func TearDownLogger() {
+	// Errors found while generating build actions
+	reportDiagnostics()
+
 	if loggerFile != nil {
 		errCnt := logger.ErrorWarnings()
+		if err := logger.Close(); err != nil {
+			utils.Die("Failed to write '%s' file: %v", loggerFile.Name(), err)
+		}
 		loggerFile.Close()
 		if errCnt > 0 {
 			utils.Die("%d error(s) ocurred!\n\n%s\n", errCnt, logger.InfoMessage())
@@ -106,6 +144,8 @@ func Main() {
 	builder_ninja := cfg.Properties.GetBool("builder_ninja")
 	builder_android_bp := cfg.Properties.GetBool("builder_android_bp")
 	builder_android_ninja := cfg.Properties.GetBool("builder_android_ninja")
+	builder_bazel := cfg.Properties.GetBool("builder_bazel")
+	builder_cmake := cfg.Properties.GetBool("builder_cmake")
 
 	// Depend on the config file
 	pctx.AddNinjaFileDeps(env.ConfigJSON, getPathInBuildDir(".env.hash"))
@@ -211,10 +251,16 @@ func Main() {
 	ctx.RegisterBottomUpMutator("resolve_dynamic_src_outputs", resolveDynamicFileOutputs) // Cannot be parallel.
 
 	ctx.RegisterBottomUpMutator("alias", aliasMutator).Parallel()
+	ctx.RegisterBottomUpMutator("package_groups", packageGroupsMutator).Parallel()
+	ctx.RegisterBottomUpMutator("package", packageMutator).Parallel()
 	ctx.RegisterBottomUpMutator("generated", generatedDependerMutator).Parallel()
 	ctx.RegisterBottomUpMutator("collect_metadata", metaDataCollector).Parallel()
 
-	if handler := initGrapvizHandler(); handler != nil {
+	if lintMode {
+		// Single threaded, to report the checks in a stable order
+		ctx.RegisterBottomUpMutator("lint", lintMutator)
+		ctx.RegisterBottomUpMutator("lint_report", lintReportMutator)
+	} else if handler := initGrapvizHandler(); handler != nil {
 		ctx.RegisterBottomUpMutator("graphviz_output", handler.graphvizMutator)
 		// Singleton for stop tool and don't overwrite build.bp
 		ctx.RegisterSingletonType("quit_singleton", handler.quitSingletonFactory)
@@ -222,10 +268,7 @@ func Main() {
 
 		ctx.RegisterTopDownMutator("export_lib_flags", exportLibFlagsMutator).Parallel()
 		dependencyGraphHandler := graphMutatorHandler{
-			map[toolchain.TgtType]graph.Graph{
-				toolchain.TgtTypeHost:   graph.NewGraph("All"),
-				toolchain.TgtTypeTarget: graph.NewGraph("All"),
-			},
+			map[toolchain.TgtType]graph.Graph{},
 		}
 		ctx.RegisterBottomUpMutator("sort_resolved_static_libs",
 			dependencyGraphHandler.ResolveDependencySortMutator) // This can't be parallel
@@ -241,20 +284,38 @@ func Main() {
 			applyReexportLibsDependenciesMutator).Parallel()
 		ctx.RegisterTopDownMutator("install_group_mutator", installGroupMutator).Parallel()
 		ctx.RegisterTopDownMutator("debug_info_mutator", debugInfoMutator).Parallel()
-		if !builder_android_bp {
-			// The android_bp backend's escape function is a no-op,
-			// so optimize by skipping the mutator
+		if !builder_android_bp && !builder_bazel && !builder_cmake {
+			// The android_bp, bazel and cmake backends' escape functions are
+			// no-ops, so optimize by skipping the mutator
 			ctx.RegisterTopDownMutator("escape_mutator", escapeMutator).Parallel()
 		}
 		ctx.RegisterTopDownMutator("late_template_mutator", lateTemplateMutator).Parallel()
 	}
 
+	// Report the errors found by the mutators above, all at once
+	ctx.RegisterBottomUpMutator("report_diagnostics", reportDiagnosticsMutator)
+
 	SetupLogger(env)
+	if lintMode {
+		// Report every category not filtered otherwise
+		logger.SetDefaultAction(warnings.WarningAction)
+	}
 	defer TearDownLogger()
 	defer MetaDataWriteToFile(env.BuildMetaFile)
+	defer CompileCommandsWriteToFile(env.CompileCommandsFile)
+
+	// Reuse the compiler flag probes of the previous run
+	toolchain.LoadFlagCache(getPathInBuildDir(".flag_cache.json"))
+	defer toolchain.SaveFlagCache()
 
 	if builder_ninja {
 		cfg.Generator = &linuxGenerator{}
+
+		ctx.RegisterSingletonType("bob_tests_singleton", linuxTestsSingletonFactory)
+		ctx.RegisterSingletonType("bob_tidy_singleton", linuxTidySingletonFactory)
+		ctx.RegisterSingletonType("bob_coverage_singleton", linuxCoverageSingletonFactory)
+		ctx.RegisterSingletonType("bob_install_manifest_singleton", linuxInstallManifestSingletonFactory)
+		ctx.RegisterSingletonType("bob_symbol_index_singleton", linuxSymbolIndexSingletonFactory)
 	} else if builder_android_bp {
 		cfg.Generator = &androidBpGenerator{}
 
@@ -263,11 +324,25 @@ func Main() {
 		ctx.RegisterSingletonType("androidbp_singleton", androidBpSingletonFactory)
 	} else if builder_android_ninja {
 		cfg.Generator = &androidNinjaGenerator{}
+	} else if builder_bazel {
+		cfg.Generator = &bazelGenerator{}
+
+		// Do not run in parallel to avoid locking issues on the maps
+		ctx.RegisterBottomUpMutator("collect_bazel_packages", collectBazelPackagesMutator)
+		ctx.RegisterSingletonType("bazel_singleton", bazelSingletonFactory)
+	} else if builder_cmake {
+		cfg.Generator = &cmakeGenerator{}
+
+		ctx.RegisterSingletonType("cmake_singleton", cmakeSingletonFactory)
 	} else {
 		utils.Die("Unknown builder backend")
 	}
 
 	// It is safe to call `backend.Get()` after this call.
 	backend.Setup(env, &cfg.Properties)
+	checkVariantNames(cfg)
+	// The blocks of clashing variants would hide module properties, so
+	// stop before any module is loaded
+	reportDiagnostics()
 	bootstrap.Main(ctx, cfg)
 }
@@ -70,9 +70,10 @@ func StrictBinaryFactory(config *BobConfig) (blueprint.Module, []interface{}) {
 
 	module := &ModuleStrictBinary{}
 	module.Properties.Linkstatic = &t // always true for executables
-	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, SplittableProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{})
-	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TagableProps{})
-	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TagableProps{})
+	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, SplittableProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
 	return module, []interface{}{&module.Properties,
 		&module.SimpleName.Properties}
 }
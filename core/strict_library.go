@@ -3,6 +3,7 @@ package core
 import (
 	"regexp"
 
+	"github.com/ARM-software/bob-build/core/backend"
 	"github.com/ARM-software/bob-build/core/file"
 	"github.com/ARM-software/bob-build/core/flag"
 	"github.com/ARM-software/bob-build/core/module"
@@ -39,6 +40,11 @@ type StrictLibraryProps struct {
 	Copts         []string
 	Deps          []string
 
+	// Check that the sources only include headers of the libraries in
+	// `deps`, as Bazel's `layering_check` does. Defaults to the
+	// `LAYERING_CHECK` configuration option.
+	Layering_check *bool
+
 	// TODO: unused but needed for the output interface, no easy way to hide it
 	Out *string
 }
@@ -60,6 +66,8 @@ type ModuleStrictLibrary struct {
 		IncludeProps
 		TransitiveLibraryProps
 		TagableProps
+		TidyProps
+		CoverageProps
 
 		Features
 		EnableableProps
@@ -69,6 +77,7 @@ type ModuleStrictLibrary struct {
 		TargetType toolchain.TgtType `blueprint:"mutated"`
 		Target     TargetSpecific
 		Host       TargetSpecific
+		VariantSpecific
 
 		ToolchainFlagsProps
 	}
@@ -95,6 +104,20 @@ func (m *ModuleStrictLibrary) processPaths(ctx blueprint.BaseModuleContext) {
 	m.Properties.Includes = utils.PrefixDirs(m.Properties.Includes, prefix)
 }
 
+func (m *ModuleStrictLibrary) getEscapeProperties() []*[]string {
+	return []*[]string{
+		&m.Properties.Tidy_flags,
+	}
+}
+
+func (m *ModuleStrictLibrary) getTidyProps() *TidyProps {
+	return &m.Properties.TidyProps
+}
+
+func (m *ModuleStrictLibrary) getCoverageProps() *CoverageProps {
+	return &m.Properties.CoverageProps
+}
+
 func (m *ModuleStrictLibrary) outputName() string {
 	if m.Properties.Out != nil {
 		return *m.Properties.Out
@@ -232,6 +255,8 @@ func (m *ModuleStrictLibrary) FeaturableProperties() []interface{} {
 		&m.Properties.InstallableProps,
 		&m.Properties.IncludeProps,
 		&m.Properties.TagableProps,
+		&m.Properties.TidyProps,
+		&m.Properties.CoverageProps,
 	}
 }
 
@@ -242,6 +267,8 @@ func (m *ModuleStrictLibrary) targetableProperties() []interface{} {
 		&m.Properties.InstallableProps,
 		&m.Properties.IncludeProps,
 		&m.Properties.TagableProps,
+		&m.Properties.TidyProps,
+		&m.Properties.CoverageProps,
 	}
 }
 
@@ -268,7 +295,7 @@ func (m *ModuleStrictLibrary) supportedVariants() (tgts []toolchain.TgtType) {
 		tgts = append(tgts, toolchain.TgtTypeHost)
 	}
 	if m.isTargetSupported() {
-		tgts = append(tgts, toolchain.TgtTypeTarget)
+		tgts = append(tgts, backend.Get().TargetVariants()...)
 	}
 
 	return
@@ -279,6 +306,8 @@ func (m *ModuleStrictLibrary) getTargetSpecific(tgt toolchain.TgtType) *TargetSp
 		return &m.Properties.Host
 	} else if tgt == toolchain.TgtTypeTarget {
 		return &m.Properties.Target
+	} else if variant := m.Properties.VariantSpecific.get(tgt); variant != nil {
+		return variant
 	} else {
 		utils.Die("Unsupported target type: %s", tgt)
 	}
@@ -394,9 +423,10 @@ func LibraryFactory(config *BobConfig) (blueprint.Module, []interface{}) {
 	module := &ModuleStrictLibrary{}
 	module.Properties.Linkstatic = &t //Default to static
 
-	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, EnableableProps{}, InstallableProps{}, SplittableProps{}, IncludeProps{}, TagableProps{})
-	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{})
-	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{})
+	module.Properties.Features.Init(&config.Properties, StrictLibraryProps{}, EnableableProps{}, InstallableProps{}, SplittableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Host.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.Target.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
+	module.Properties.VariantSpecific.init(&config.Properties, StrictLibraryProps{}, InstallableProps{}, EnableableProps{}, IncludeProps{}, TagableProps{}, TidyProps{}, CoverageProps{})
 
 	return module, []interface{}{&module.Properties,
 		&module.SimpleName.Properties}
@@ -1,9 +1,12 @@
 package core
 
 import (
+	"path/filepath"
+
 	"github.com/ARM-software/bob-build/core/tag"
 	"github.com/ARM-software/bob-build/core/toolchain"
 	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
 )
 
 type StripProps struct {
@@ -21,6 +24,10 @@ type StripProps struct {
 	// The path retrieved from debug install group so we don't need to
 	// walk dependencies to get it
 	Debug_path *string `blueprint:"mutated"`
+	// The layout of the debug information, retrieved from the debug
+	// install group
+	Debug_build_id bool `blueprint:"mutated"`
+	Debug_compress bool `blueprint:"mutated"`
 }
 
 func (props *StripProps) getDebugInfo() *string {
@@ -35,6 +42,15 @@ func (props *StripProps) setDebugPath(path *string) {
 	props.Debug_path = path
 }
 
+func (props *StripProps) getDebugInfoLayout() (buildID bool, compress bool) {
+	return props.Debug_build_id, props.Debug_compress
+}
+
+func (props *StripProps) setDebugInfoLayout(buildID bool, compress bool) {
+	props.Debug_build_id = buildID
+	props.Debug_compress = compress
+}
+
 type stripable interface {
 	strip() bool
 	getTarget() toolchain.TgtType
@@ -43,6 +59,8 @@ type stripable interface {
 	getDebugInfo() *string
 	getDebugPath() *string
 	setDebugPath(*string)
+	getDebugInfoLayout() (bool, bool)
+	setDebugInfoLayout(bool, bool)
 }
 
 // A module which can return a stripable object such as `bob_toolchain`.
@@ -53,7 +71,31 @@ type StripCapable interface {
 
 func debugInfoMutator(ctx blueprint.TopDownMutatorContext) {
 	if m, ok := ctx.Module().(stripable); ok {
-		path := getInstallGroupPathFromTag(ctx, tag.DebugInfoTag)
-		m.setDebugPath(path)
+		insg := getInstallGroupFromTag(ctx, tag.DebugInfoTag)
+		if insg == nil {
+			m.setDebugPath(nil)
+			return
+		}
+		m.setDebugPath(insg.installPath(ctx))
+		m.setDebugInfoLayout(insg.debugBuildID(),
+			proptools.Bool(insg.Properties.Compress_debug_sections))
+	}
+}
+
+// Returns the strip.py arguments which write the debug information of
+// `basename` to `debugDir`. With the build-id layout, the name of the debug
+// file is only known once the binary is linked, so strip.py chooses it.
+func debugInfoArgs(lib stripable, debugDir, basename string) []string {
+	buildID, compress := lib.getDebugInfoLayout()
+
+	args := []string{}
+	if buildID {
+		args = append(args, "--build-id-dir", debugDir)
+	} else {
+		args = append(args, "--debug-file", filepath.Join(debugDir, basename+".dbg"))
+	}
+	if compress {
+		args = append(args, "--compress-debug-sections")
 	}
+	return args
 }
@@ -19,6 +19,7 @@ var (
 	InstallGroupTag           = DependencyTag{Name: "install_group"}
 	InstallTag                = DependencyTag{Name: "install_dep"}
 	KernelModuleTag           = DependencyTag{Name: "kernel_module"}
+	PackageTag                = DependencyTag{Name: "package"}
 	ReexportLibraryTag        = DependencyTag{Name: "reexport_libs"}
 	SharedTag                 = DependencyTag{Name: "shared"}
 	StaticTag                 = DependencyTag{Name: "static"}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// TidyProps control running clang-tidy on the sources of a module. They
+// match the properties of the same name in Soong.
+type TidyProps struct {
+	// Whether to run clang-tidy on the module's C and C++ sources.
+	Tidy *bool
+	// Checks to enable or disable, e.g. `-*` or `bugprone-*`.
+	Tidy_checks []string
+	// Checks whose warnings are reported as errors.
+	Tidy_checks_as_errors []string
+	// Additional flags passed to clang-tidy.
+	Tidy_flags []string
+}
+
+// Modules implementing tidyable can have clang-tidy run on their sources.
+type tidyable interface {
+	Compilable
+	getTidyProps() *TidyProps
+}
+
+func (p *TidyProps) tidyEnabled() bool {
+	return proptools.Bool(p.Tidy)
+}
+
+// Arguments for clang-tidy selecting the checks to run.
+func (p *TidyProps) tidyCheckFlags() (flags []string) {
+	if len(p.Tidy_checks) > 0 {
+		flags = append(flags, "-checks="+strings.Join(p.Tidy_checks, ","))
+	}
+	if len(p.Tidy_checks_as_errors) > 0 {
+		flags = append(flags, "-warnings-as-errors="+strings.Join(p.Tidy_checks_as_errors, ","))
+	}
+	return
+}
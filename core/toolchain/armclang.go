@@ -77,6 +77,20 @@ func (tc toolchainArmClang) CheckFlagIsSupported(language, flag string) bool {
 	return tc.flagCache.checkFlag(tc, language, flag)
 }
 
+// Arm Compiler does not provide the sanitizer runtimes.
+func (tc toolchainArmClang) GetSanitizerFlags(sanitizers []string) ([]string, []string) {
+	return nil, nil
+}
+
+func (tc toolchainArmClang) GetCoverageFlags() ([]string, []string) {
+	// Arm Compiler does not provide a gcov-compatible coverage runtime
+	return nil, nil
+}
+
+func (tc toolchainArmClang) GetGcov() (string, []string) {
+	return "", []string{}
+}
+
 func (tc toolchainArmClang) Is64BitOnly() bool {
 	return tc.is64BitOnly
 }
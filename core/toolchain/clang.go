@@ -15,6 +15,7 @@ type toolchainClangCommon struct {
 	arBinary       string
 	asBinary       string
 	nmBinary       string
+	gcovBinary     string
 	ranlibBinary   string
 	objcopyBinary  string
 	objdumpBinary  string
@@ -143,6 +144,25 @@ func (tc toolchainClangCommon) CheckFlagIsSupported(language, flag string) bool
 	return tc.flagCache.checkFlag(tc, language, flag)
 }
 
+func (tc toolchainClangCommon) GetSanitizerFlags(sanitizers []string) ([]string, []string) {
+	cflags, ldflags := sanitizerFlags(sanitizers)
+	if utils.Contains(sanitizers, "memory") {
+		// Without origin tracking, MemorySanitizer reports are hard to act on.
+		cflags = append(cflags, "-fsanitize-memory-track-origins")
+	}
+	return cflags, ldflags
+}
+
+func (tc toolchainClangCommon) GetCoverageFlags() ([]string, []string) {
+	return coverageFlags()
+}
+
+// Clang writes notes in its own variant of the gcov format, which only
+// `llvm-cov gcov` reads, even when the GNU binutils are used.
+func (tc toolchainClangCommon) GetGcov() (string, []string) {
+	return tc.gcovBinary, []string{}
+}
+
 func (tc toolchainClangCommon) Is64BitOnly() bool {
 	return tc.is64BitOnly
 }
@@ -155,6 +175,7 @@ func newToolchainClangCommon(props *config.Properties, tgt TgtType) (tc toolchai
 	tc.arBinary = props.GetString(string(tgt) + "_ar_binary")
 	tc.asBinary = tc.prefix + props.GetString("as_binary")
 	tc.nmBinary = props.GetString(string(tgt) + "_nm_binary")
+	tc.gcovBinary = props.GetString(string(tgt) + "_gcov_binary")
 	tc.ranlibBinary = props.GetString(string(tgt) + "_ranlib_binary")
 
 	tc.objcopyBinary = props.GetString(string(tgt) + "_objcopy_binary")
@@ -165,9 +186,16 @@ func newToolchainClangCommon(props *config.Properties, tgt TgtType) (tc toolchai
 
 	tc.target = props.GetString(string(tgt) + "_clang_triple")
 
+	// The GNU toolchain, if used, shares the toolchain root
+	root := getToolchainRoot(props, tgt)
+	for _, tool := range []*string{&tc.arBinary, &tc.asBinary, &tc.nmBinary, &tc.gcovBinary,
+		&tc.ranlibBinary, &tc.objcopyBinary, &tc.objdumpBinary, &tc.clangBinary, &tc.clangxxBinary} {
+		*tool = pinTool(root, *tool)
+	}
+
 	// Here we add flags relating to android out of tree builds
 	if out_of_tree := props.GetBool("builder_android_ninja"); out_of_tree {
-		fuseLdFlag := selectSupportedFuseLdFlag(tc.clangBinary, []string{"lld", "gold", "bfd"})
+		fuseLdFlag := selectSupportedFuseLdFlag(root, tc.clangBinary, []string{"lld", "gold", "bfd"})
 
 		tc.cflags = append(tc.cflags, "-DANDROID")
 		tc.cflags = append(tc.cflags, "-Wno-unused-but-set-variable")
@@ -527,7 +555,7 @@ func newToolchainClangCommon(props *config.Properties, tgt TgtType) (tc toolchai
 		tc.ldflags = append(tc.ldflags, ldflags)
 	}
 
-	sysroot := props.GetString(string(tgt) + "_sysroot")
+	sysroot := getSysroot(props, tgt, root)
 	if sysroot != "" {
 		tc.cflags = append(tc.cflags, "--sysroot="+sysroot)
 		tc.ldflags = append(tc.ldflags, "--sysroot="+sysroot)
@@ -629,9 +657,9 @@ func newToolchainClangCommon(props *config.Properties, tgt TgtType) (tc toolchai
 	return
 }
 
-func selectSupportedFuseLdFlag(compiler string, candidates []string) string {
+func selectSupportedFuseLdFlag(root, compiler string, candidates []string) string {
 	for _, candidate := range candidates {
-		if !linkerExists(compiler, candidate) {
+		if !linkerExists(root, compiler, candidate) {
 			continue
 		}
 		if fuseLdSupported(compiler, candidate) {
@@ -641,11 +669,22 @@ func selectSupportedFuseLdFlag(compiler string, candidates []string) string {
 	return ""
 }
 
-func linkerExists(compiler, linker string) bool {
+// linkerExists returns whether a linker is in PATH or next to the
+// compiler. A hermetic toolchain's linker must be in the `bin` directory of
+// its root.
+func linkerExists(root, compiler, linker string) bool {
 	names := []string{"ld." + linker}
 	if linker == "lld" {
 		names = append(names, "lld")
 	}
+	if root != "" {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(root, "bin", name)); err == nil {
+				return true
+			}
+		}
+		return false
+	}
 	for _, name := range names {
 		if _, err := exec.LookPath(name); err == nil {
 			return true
@@ -9,6 +9,7 @@ type toolchainCustom struct {
 	arBinary      string
 	asBinary      string
 	nmBinary      string
+	gcovBinary    string
 	ranlibBinary  string
 	objcopyBinary string
 	objdumpBinary string
@@ -73,6 +74,18 @@ func (tc toolchainCustom) CheckFlagIsSupported(language, flag string) bool {
 	return tc.flagCache.checkFlag(tc, language, flag)
 }
 
+func (tc toolchainCustom) GetSanitizerFlags(sanitizers []string) ([]string, []string) {
+	return sanitizerFlags(sanitizers)
+}
+
+func (tc toolchainCustom) GetCoverageFlags() ([]string, []string) {
+	return coverageFlags()
+}
+
+func (tc toolchainCustom) GetGcov() (string, []string) {
+	return tc.gcovBinary, []string{}
+}
+
 func (tc toolchainCustom) Is64BitOnly() bool {
 	return tc.is64BitOnly
 }
@@ -81,6 +94,7 @@ func newToolchainCustom(props *config.Properties, tgt TgtType) (tc toolchainCust
 	tc.arBinary = props.GetString(string(tgt) + "_ar_binary")
 	tc.asBinary = props.GetString("as_binary")
 	tc.nmBinary = props.GetString(string(tgt) + "_nm_binary")
+	tc.gcovBinary = props.GetString(string(tgt) + "_gcov_binary")
 	tc.ranlibBinary = props.GetString(string(tgt) + "_ranlib_binary")
 
 	tc.objcopyBinary = props.GetString(string(tgt) + "_objcopy_binary")
@@ -0,0 +1,173 @@
+package toolchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// The results of flag probes are kept between runs in a file in the build
+// directory, so that regenerating does not run the compilers again. They are
+// stored per compiler, and dropped when the compiler binary or its version
+// changes.
+type persistedCompiler struct {
+	Mtime   int64           `json:"mtime"`
+	Version string          `json:"version"` // SHA-256 of the `--version` output
+	Flags   map[string]bool `json:"flags"`   // Keyed by the probe's arguments
+}
+
+// compilerIdentity identifies the binary a compiler runs, or is empty if
+// the binary cannot be found, in which case its probes are not persisted.
+type compilerIdentity struct {
+	path    string
+	mtime   int64
+	version string
+}
+
+var persistentFlagCache = struct {
+	file       string
+	compilers  map[string]*persistedCompiler // Keyed by the binary's path
+	identities map[string]compilerIdentity   // Keyed by the configured compiler
+	changed    bool
+	lock       sync.Mutex
+}{
+	compilers:  map[string]*persistedCompiler{},
+	identities: map[string]compilerIdentity{},
+}
+
+// LoadFlagCache reads the flag probes persisted in file by a previous run,
+// and enables persisting them. A missing or unreadable file is treated as an
+// empty cache.
+func LoadFlagCache(file string) {
+	cache := &persistentFlagCache
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	cache.file = file
+	if data, err := ioutil.ReadFile(file); err == nil {
+		compilers := map[string]*persistedCompiler{}
+		if json.Unmarshal(data, &compilers) == nil {
+			cache.compilers = compilers
+		}
+	}
+}
+
+// SaveFlagCache writes the flag probes to the file given to LoadFlagCache,
+// if any were added. The probes are only kept to save time, so failing to
+// write them is a warning.
+func SaveFlagCache() {
+	cache := &persistentFlagCache
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cache.file == "" || !cache.changed {
+		return
+	}
+
+	data, err := json.MarshalIndent(cache.compilers, "", "  ")
+	if err == nil {
+		err = writeFileAtomically(cache.file, data)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save flag probes to '%s': %v\n", cache.file, err)
+		return
+	}
+	cache.changed = false
+}
+
+// writeFileAtomically replaces a file by renaming a temporary file over it,
+// so that an interrupted write does not leave a truncated file.
+func writeFileAtomically(file string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	return err
+}
+
+// getCompilerIdentity finds the binary of a compiler, and its version. This
+// is done once per compiler and run. The caller holds the lock.
+func getCompilerIdentity(compiler string) compilerIdentity {
+	cache := &persistentFlagCache
+	if id, ok := cache.identities[compiler]; ok {
+		return id
+	}
+
+	id := compilerIdentity{}
+	if path, err := exec.LookPath(compiler); err == nil {
+		if fi, err := os.Stat(path); err == nil {
+			if out, err := exec.Command(path, "--version").CombinedOutput(); err == nil {
+				sum := sha256.Sum256(out)
+				id = compilerIdentity{path, fi.ModTime().UnixNano(), hex.EncodeToString(sum[:])}
+			}
+		}
+	}
+
+	cache.identities[compiler] = id
+	return id
+}
+
+// getPersistedCompiler returns the probes of a compiler, discarding those
+// made with a different binary or version. It returns nil if the compiler's
+// probes are not persisted. The caller holds the lock.
+func getPersistedCompiler(compiler string) *persistedCompiler {
+	cache := &persistentFlagCache
+	if cache.file == "" {
+		return nil
+	}
+
+	id := getCompilerIdentity(compiler)
+	if id.path == "" {
+		return nil
+	}
+
+	c, ok := cache.compilers[id.path]
+	if !ok || c.Mtime != id.mtime || c.Version != id.version || c.Flags == nil {
+		c = &persistedCompiler{Mtime: id.mtime, Version: id.version, Flags: map[string]bool{}}
+		cache.compilers[id.path] = c
+		cache.changed = true
+	}
+	return c
+}
+
+// lookupPersistedFlag returns the result of a probe made by a previous run.
+func lookupPersistedFlag(compiler, probe string) (supported bool, ok bool) {
+	cache := &persistentFlagCache
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if c := getPersistedCompiler(compiler); c != nil {
+		supported, ok = c.Flags[probe]
+	}
+	return
+}
+
+// persistFlag records the result of a probe for the following runs.
+func persistFlag(compiler, probe string, supported bool) {
+	cache := &persistentFlagCache
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if c := getPersistedCompiler(compiler); c != nil {
+		c.Flags[probe] = supported
+		cache.changed = true
+	}
+}
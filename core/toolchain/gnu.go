@@ -22,6 +22,7 @@ type toolchainGnuCommon struct {
 	arBinary      string
 	asBinary      string
 	nmBinary      string
+	gcovBinary    string
 	ranlibBinary  string
 	objcopyBinary string
 	objdumpBinary string
@@ -95,6 +96,11 @@ func (tc toolchainGnuCommon) CheckFlagIsSupported(language, flag string) bool {
 	return tc.flagCache.checkFlag(tc, language, flag)
 }
 
+// GCC has no MemorySanitizer, so requesting it is caught by the flag check.
+func (tc toolchainGnuCommon) GetSanitizerFlags(sanitizers []string) ([]string, []string) {
+	return sanitizerFlags(sanitizers)
+}
+
 // The libstdc++ headers shipped with GCC toolchains are stored, relative to
 // the `prefix-gcc` binary's location, in `../$ARCH/include/c++/$VERSION` and
 // `../$ARCH/include/c++/$VERSION/$ARCH`. This function returns $ARCH. This is
@@ -137,6 +143,14 @@ func (tc toolchainGnuCommon) getInstallDir() string {
 	return filepath.Dir(tc.binDir)
 }
 
+func (tc toolchainGnuCommon) GetCoverageFlags() ([]string, []string) {
+	return coverageFlags()
+}
+
+func (tc toolchainGnuCommon) GetGcov() (string, []string) {
+	return tc.gcovBinary, []string{}
+}
+
 func (tc toolchainGnuCommon) Is64BitOnly() bool {
 	return tc.is64BitOnly
 }
@@ -200,7 +214,7 @@ func toolExists(tool string) bool {
 	return err == nil
 }
 
-func maybeInferWrapper(tool, ccBinary, wrapper string) string {
+func maybeInferWrapper(tool, ccBinary, wrapper, root string) string {
 	if filepath.Base(tool) != "gcc-"+wrapper {
 		return tool
 	}
@@ -221,8 +235,8 @@ func maybeInferWrapper(tool, ccBinary, wrapper string) string {
 	}
 
 	// Last resort: plain tool name.
-	if toolExists(wrapper) {
-		return wrapper
+	if plain := pinTool(root, wrapper); toolExists(plain) {
+		return plain
 	}
 	return tool
 }
@@ -266,6 +280,7 @@ func newToolchainGnuCommon(props *config.Properties, tgt TgtType) (tc toolchainG
 	tc.arBinary = props.GetString(string(tgt) + "_ar_binary")
 	tc.asBinary = tc.prefix + props.GetString("as_binary")
 	tc.nmBinary = props.GetString(string(tgt) + "_nm_binary")
+	tc.gcovBinary = props.GetString(string(tgt) + "_gcov_binary")
 	tc.ranlibBinary = props.GetString(string(tgt) + "_ranlib_binary")
 
 	tc.objcopyBinary = props.GetString(string(tgt) + "_objcopy_binary")
@@ -274,13 +289,27 @@ func newToolchainGnuCommon(props *config.Properties, tgt TgtType) (tc toolchainG
 	tc.gccBinary = tc.prefix + props.GetString(string(tgt)+"_gnu_cc_binary")
 	tc.gxxBinary = tc.prefix + props.GetString(string(tgt)+"_gnu_cxx_binary")
 
+	// In a hermetic toolchain, every tool is in the toolchain root, so
+	// neither the wrappers nor the compiler are looked up in PATH.
+	root := getToolchainRoot(props, tgt)
+	if root != "" {
+		for _, tool := range []*string{&tc.arBinary, &tc.asBinary, &tc.nmBinary, &tc.gcovBinary,
+			&tc.ranlibBinary, &tc.objcopyBinary, &tc.objdumpBinary, &tc.gccBinary, &tc.gxxBinary} {
+			*tool = pinTool(root, *tool)
+		}
+	}
+
 	// If the prefix is empty, derive gcc-* wrappers from the compiler name.
 	if tc.prefix == "" {
-		tc.arBinary = maybeInferWrapper(tc.arBinary, tc.gccBinary, "ar")
-		tc.nmBinary = maybeInferWrapper(tc.nmBinary, tc.gccBinary, "nm")
-		tc.ranlibBinary = maybeInferWrapper(tc.ranlibBinary, tc.gccBinary, "ranlib")
+		tc.arBinary = maybeInferWrapper(tc.arBinary, tc.gccBinary, "ar", root)
+		tc.nmBinary = maybeInferWrapper(tc.nmBinary, tc.gccBinary, "nm", root)
+		tc.ranlibBinary = maybeInferWrapper(tc.ranlibBinary, tc.gccBinary, "ranlib", root)
+	}
+	if root != "" {
+		tc.binDir = filepath.Dir(tc.gccBinary)
+	} else {
+		tc.binDir = filepath.Dir(getToolPath(tc.gccBinary))
 	}
-	tc.binDir = filepath.Dir(getToolPath(tc.gccBinary))
 
 	if cflags := props.GetStringIfExists(string(tgt) + "_cflags"); cflags != "" {
 		tc.cflags = append(tc.cflags, cflags)
@@ -294,7 +323,7 @@ func newToolchainGnuCommon(props *config.Properties, tgt TgtType) (tc toolchainG
 		tc.ldflags = append(tc.ldflags, ldflags)
 	}
 
-	sysroot := props.GetString(string(tgt) + "_sysroot")
+	sysroot := getSysroot(props, tgt, root)
 	if sysroot != "" {
 		tc.cflags = append(tc.cflags, "--sysroot="+sysroot)
 		tc.ldflags = append(tc.ldflags, "--sysroot="+sysroot)
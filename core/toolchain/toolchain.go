@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -21,6 +22,42 @@ const (
 	TgtTypeUnknown TgtType = ""
 )
 
+// IsTarget returns whether the type is a target variant, i.e. `target`
+// itself or one of the variants set by `target_variants`.
+func (t TgtType) IsTarget() bool {
+	return t != TgtTypeHost && t != TgtTypeUnknown
+}
+
+var variantNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// TargetVariants returns the target variants listed in the
+// `target_variants` configuration option. When it is empty, there is a
+// single `target` variant.
+func TargetVariants(props *config.Properties) []TgtType {
+	names := strings.Fields(props.GetStringIfExists("target_variants"))
+	if len(names) == 0 {
+		return []TgtType{TgtTypeTarget}
+	}
+
+	variants := []TgtType{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		// `target` refers to the primary variant, so cannot name one
+		if !variantNameRegexp.MatchString(name) || name == string(TgtTypeHost) || name == string(TgtTypeTarget) {
+			utils.Die("Invalid target variant '%s' in target_variants", name)
+		}
+		if seen[name] {
+			utils.Die("Target variant '%s' is listed twice in target_variants", name)
+		}
+		if _, ok := props.Features[name]; ok {
+			utils.Die("Target variant '%s' has the same name as a feature", name)
+		}
+		seen[name] = true
+		variants = append(variants, TgtType(name))
+	}
+	return variants
+}
+
 type Toolchain interface {
 	GetArchiver() (tool string, flags []string)
 	GetAssembler() (tool string, flags []string)
@@ -32,6 +69,9 @@ type Toolchain interface {
 	GetStripFlags() []string
 	GetLibraryTocFlags() []string
 	CheckFlagIsSupported(language, flag string) bool
+	GetSanitizerFlags(sanitizers []string) (cflags []string, ldflags []string)
+	GetCoverageFlags() (cflags []string, ldflags []string)
+	GetGcov() (tool string, flags []string)
 	Is64BitOnly() bool
 }
 
@@ -92,6 +132,36 @@ func getToolPath(toolUnqualified string) string {
 	return realToolPath
 }
 
+// getToolchainRoot returns the directory of a hermetic toolchain, whose
+// tools are not looked up in PATH, or "" if none is configured.
+func getToolchainRoot(props *config.Properties, tgt TgtType) string {
+	root := props.GetStringIfExists(string(tgt) + "_toolchain_root")
+	if root != "" && !filepath.IsAbs(root) {
+		panic(fmt.Errorf("%s_toolchain_root must be an absolute path, not %s", tgt, root))
+	}
+	return root
+}
+
+// pinTool returns the path of a tool in the `bin` directory of a hermetic
+// toolchain. Tools configured with a directory are left as they are, as is
+// every tool when there is no toolchain root.
+func pinTool(root, tool string) string {
+	if root == "" || tool == "" || strings.ContainsRune(tool, os.PathSeparator) {
+		return tool
+	}
+	return filepath.Join(root, "bin", tool)
+}
+
+// getSysroot returns the configured sysroot. In a hermetic toolchain, a
+// relative sysroot is inside the toolchain root.
+func getSysroot(props *config.Properties, tgt TgtType, root string) string {
+	sysroot := props.GetString(string(tgt) + "_sysroot")
+	if root != "" && sysroot != "" && !filepath.IsAbs(sysroot) {
+		sysroot = filepath.Join(root, sysroot)
+	}
+	return sysroot
+}
+
 // Run the compiler with the -print-file-name option, and return the result.
 // Check that the file exists. Return an error if the file can't be located.
 func getFileName(tc Toolchain, basename string) (fname string, e error) {
@@ -179,51 +249,109 @@ func (cache *flagSupportedCache) checkFlag(tc Toolchain, language, flag string)
 	saneFlag := strings.Replace(flag, "-Wno-", "-W", 1)
 	testFlags := utils.NewStringSlice(flags, []string{"-x", language, "-c", os.DevNull, "-o", os.DevNull, "-Werror", saneFlag})
 	testFlags = utils.Remove(testFlags, "")
-	cmd := exec.Command(compiler, testFlags...)
-	_, err := cmd.CombinedOutput()
-	if err == nil {
-		cache.lock.Lock()
-		cache.m[key] = true
-		cache.lock.Unlock()
-		return true
+
+	// A previous run may have made the same probe
+	probe := strings.Join(testFlags, " ")
+	supported, ok = lookupPersistedFlag(compiler, probe)
+	if !ok {
+		cmd := exec.Command(compiler, testFlags...)
+		_, err := cmd.CombinedOutput()
+		// If err is set, the compiler did not recognise the flag
+		supported = err == nil
+		persistFlag(compiler, probe, supported)
 	}
 
-	// Compiler did not recognise the flag
 	cache.lock.Lock()
-	cache.m[key] = false
+	cache.m[key] = supported
 	cache.lock.Unlock()
 
-	return false
+	return supported
+}
+
+// Flags enabling sanitizers on compilers accepting GCC-style `-fsanitize=`
+// options. The same options must be passed when linking, so that the
+// sanitizer runtimes are pulled in. Frame pointers are kept to give the
+// runtimes usable stack traces.
+func sanitizerFlags(sanitizers []string) (cflags []string, ldflags []string) {
+	if len(sanitizers) == 0 {
+		return
+	}
+	for _, s := range sanitizers {
+		cflags = append(cflags, "-fsanitize="+s)
+		ldflags = append(ldflags, "-fsanitize="+s)
+	}
+	cflags = append(cflags, "-fno-omit-frame-pointer")
+	return
+}
+
+// Flags instrumenting code for gcov-compatible coverage. The compiler writes
+// a `.gcno` notes file next to each object, and instrumented programs write
+// the matching `.gcda` data files when they exit.
+func coverageFlags() (cflags []string, ldflags []string) {
+	return []string{"--coverage"}, []string{"--coverage"}
 }
 
 type ToolchainSet struct {
-	host   Toolchain
-	target Toolchain
+	host Toolchain
+
+	// The toolchain of each target variant, in `target_variants` order
+	variants []TgtType
+	targets  map[TgtType]Toolchain
 }
 
+// GetToolchain returns the toolchain of a variant. Target types which are
+// not a variant, such as `target` in a multilib build, use the toolchain
+// of the first target variant.
 func (tcs *ToolchainSet) GetToolchain(tgt TgtType) Toolchain {
 	if tgt == TgtTypeHost {
 		return tcs.host
 	}
-	return tcs.target
+	if tc, ok := tcs.targets[tgt]; ok {
+		return tc
+	}
+	return tcs.targets[tcs.variants[0]]
 }
 
-func (tcs *ToolchainSet) Configure(props *config.Properties) {
-	if props.GetBool("custom_toolchain") {
-		tcs.target = newToolchainCustomCross(props)
-		tcs.host = newToolchainCustomNative(props)
-		return
-	}
+// TargetVariants returns the configured target variants.
+func (tcs *ToolchainSet) TargetVariants() []TgtType {
+	return tcs.variants
+}
+
+func newTargetToolchain(props *config.Properties) Toolchain {
 	if props.GetBool("target_toolchain_clang") {
-		tcs.target = newToolchainClangCross(props)
+		return newToolchainClangCross(props)
 	} else if props.GetBool("target_toolchain_gnu") {
-		tcs.target = newToolchainGnuCross(props)
+		return newToolchainGnuCross(props)
 	} else if props.GetBool("target_toolchain_armclang") {
-		tcs.target = newToolchainArmClangCross(props)
+		return newToolchainArmClangCross(props)
 	} else if props.GetBool("target_toolchain_xcode") {
-		tcs.target = newToolchainXcodeCross(props)
-	} else {
-		panic(errors.New("no usable target compiler Toolchain configured"))
+		return newToolchainXcodeCross(props)
+	}
+	panic(errors.New("no usable target compiler Toolchain configured"))
+}
+
+func (tcs *ToolchainSet) Configure(props *config.Properties) {
+	tcs.variants = TargetVariants(props)
+	tcs.targets = map[TgtType]Toolchain{}
+
+	// Each target variant is configured by the `target_` options, which
+	// the variant's own options, e.g. `arm32_gnu_prefix`, override.
+	for _, variant := range tcs.variants {
+		variantProps := props
+		if variant != TgtTypeTarget {
+			variantProps = props.Rebase(string(variant), string(TgtTypeTarget))
+		}
+
+		if props.GetBool("custom_toolchain") {
+			tcs.targets[variant] = newToolchainCustomCross(variantProps)
+		} else {
+			tcs.targets[variant] = newTargetToolchain(variantProps)
+		}
+	}
+
+	if props.GetBool("custom_toolchain") {
+		tcs.host = newToolchainCustomNative(props)
+		return
 	}
 
 	if props.GetBool("host_toolchain_clang") {
@@ -0,0 +1,255 @@
+package toolchain
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ARM-software/bob-build/core/config"
+)
+
+func TestTargetVariantsDefault(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{})
+
+	assert.Equal(t, []TgtType{TgtTypeTarget}, TargetVariants(props))
+
+	props = config.CreateMockConfig(map[string]interface{}{
+		"target_variants": "",
+	})
+
+	assert.Equal(t, []TgtType{TgtTypeTarget}, TargetVariants(props))
+}
+
+func TestTargetVariants(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_variants": "arm64  arm32 x86_64",
+	})
+
+	assert.Equal(t, []TgtType{"arm64", "arm32", "x86_64"}, TargetVariants(props))
+}
+
+func TestIsTarget(t *testing.T) {
+	assert.True(t, TgtTypeTarget.IsTarget())
+	assert.True(t, TgtType("arm32").IsTarget())
+	assert.False(t, TgtTypeHost.IsTarget())
+	assert.False(t, TgtTypeUnknown.IsTarget())
+}
+
+func TestToolchainSetVariants(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_variants":   "arm64 arm32",
+		"arm32_gnu_flags":   "-m32",
+		"arm32_64bit_only":  false,
+		"target_64bit_only": true,
+	})
+
+	tcs := ToolchainSet{}
+	tcs.Configure(props)
+
+	assert.Equal(t, []TgtType{"arm64", "arm32"}, tcs.TargetVariants())
+
+	_, flags := tcs.GetToolchain("arm64").GetCCompiler()
+	assert.NotContains(t, flags, "-m32")
+	assert.True(t, tcs.GetToolchain("arm64").Is64BitOnly())
+
+	_, flags = tcs.GetToolchain("arm32").GetCCompiler()
+	assert.Contains(t, flags, "-m32")
+	assert.False(t, tcs.GetToolchain("arm32").Is64BitOnly())
+
+	// `target` is not a variant, so uses the first one
+	assert.Equal(t, tcs.GetToolchain("arm64"), tcs.GetToolchain(TgtTypeTarget))
+}
+
+func TestToolSetDefaultsToBase(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_gnu_flags": "-m64",
+	})
+	base := newTargetToolchain(props)
+
+	tc := NewToolchainFromToolSet(ToolSet{Sysroot: "/sysroot"}, base)
+
+	baseCC, _ := base.GetCCompiler()
+	cc, flags := tc.GetCCompiler()
+	assert.Equal(t, baseCC, cc)
+	assert.Equal(t, []string{"-m64", "--sysroot=/sysroot"}, flags)
+
+	baseAr, _ := base.GetArchiver()
+	ar, _ := tc.GetArchiver()
+	assert.Equal(t, baseAr, ar)
+
+	assert.Equal(t, base.GetLinker().GetTool(), tc.GetLinker().GetTool())
+	assert.Contains(t, tc.GetLinker().GetFlags(), "--sysroot=/sysroot")
+}
+
+func TestToolSetRelativeSysroot(t *testing.T) {
+	base := newTargetToolchain(config.CreateMockConfig(map[string]interface{}{}))
+
+	tc := NewToolchainFromToolSet(ToolSet{Sysroot: "sysroot", Dir: "/src/fw"}, base)
+	_, flags := tc.GetCCompiler()
+	assert.Equal(t, []string{"--sysroot=/src/fw/sysroot"}, flags)
+
+	// Absolute sysroots are used as they are
+	tc = NewToolchainFromToolSet(ToolSet{Sysroot: "/sysroot", Dir: "/src/fw"}, base)
+	_, flags = tc.GetCCompiler()
+	assert.Equal(t, []string{"--sysroot=/sysroot"}, flags)
+}
+
+func TestToolSetReplacesTools(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_gnu_flags": "-m64",
+	})
+	base := newTargetToolchain(props)
+
+	tc := NewToolchainFromToolSet(ToolSet{
+		Cc:  "fw-gcc",
+		Cxx: "fw-g++",
+		Ar:  "fw-ar",
+	}, base)
+
+	// The base compiler flags are not used with other compilers
+	cc, flags := tc.GetCCompiler()
+	assert.Equal(t, "fw-gcc", cc)
+	assert.Empty(t, flags)
+
+	ar, _ := tc.GetArchiver()
+	assert.Equal(t, "fw-ar", ar)
+
+	// The linker driver defaults to the C++ compiler
+	assert.Equal(t, "fw-g++", tc.GetLinker().GetTool())
+
+	tc = NewToolchainFromToolSet(ToolSet{Cxx: "fw-g++", Ld: "fw-ld"}, base)
+	assert.Equal(t, "fw-ld", tc.GetLinker().GetTool())
+}
+
+func TestHermeticGnuToolchain(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_toolchain_root": "/opt/tc",
+		"target_sysroot":        "libc",
+		"target_objcopy_binary": "/usr/bin/objcopy",
+	})
+	tc := newToolchainGnuCross(props)
+
+	// Tools are pinned to the root rather than looked up in PATH
+	cc, flags := tc.GetCCompiler()
+	assert.Equal(t, "/opt/tc/bin/cc", cc)
+	assert.Equal(t, []string{"--sysroot=/opt/tc/libc"}, flags)
+
+	ar, _ := tc.GetArchiver()
+	assert.Equal(t, "/opt/tc/bin/ar", ar)
+	assert.Equal(t, "/opt/tc/bin/cxx", tc.GetLinker().GetTool())
+	assert.Equal(t, []string{"/opt/tc/bin"}, tc.toolchainGnuCommon.getBinDirs())
+
+	// Tools with a directory are used as configured
+	assert.Contains(t, tc.GetStripFlags(), "/usr/bin/objcopy")
+}
+
+func TestToolchainRootMustBeAbsolute(t *testing.T) {
+	props := config.CreateMockConfig(map[string]interface{}{
+		"target_toolchain_root": "opt/tc",
+	})
+
+	assert.Panics(t, func() { newToolchainGnuCross(props) })
+}
+
+func TestLinkerExistsInToolchainRoot(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "bin"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "bin", "ld.lld"), []byte{}, 0755))
+
+	// Linkers in PATH are not used with a hermetic toolchain
+	path := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(path, "ld.gold"), []byte{}, 0755))
+	t.Setenv("PATH", path)
+
+	assert.True(t, linkerExists(root, "", "lld"))
+	assert.False(t, linkerExists(root, "", "gold"))
+	assert.True(t, linkerExists("", "", "gold"))
+}
+
+// fakeCompiler writes a compiler script logging its probes to a file, and
+// rejecting `-Wbad`.
+func fakeCompiler(t *testing.T, dir string) (compiler, log string) {
+	compiler = filepath.Join(dir, "cc")
+	log = filepath.Join(dir, "probes.log")
+	script := "#!/bin/sh\n" +
+		"case \"$*\" in *--version*) echo cc 1.0; exit 0;; esac\n" +
+		"echo \"$*\" >> " + log + "\n" +
+		"case \"$*\" in *-Wbad*) exit 1;; esac\n"
+	assert.NoError(t, ioutil.WriteFile(compiler, []byte(script), 0755))
+	return
+}
+
+func countProbes(t *testing.T, log string) int {
+	data, err := ioutil.ReadFile(log)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	assert.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+// newRun forgets the state of a previous run, except for the cache file.
+func newRun(file string) {
+	persistentFlagCache.compilers = map[string]*persistedCompiler{}
+	persistentFlagCache.identities = map[string]compilerIdentity{}
+	persistentFlagCache.changed = false
+	LoadFlagCache(file)
+}
+
+func TestPersistentFlagCache(t *testing.T) {
+	dir := t.TempDir()
+	compiler, log := fakeCompiler(t, dir)
+	file := filepath.Join(dir, "flag_cache.json")
+	defer newRun("")
+
+	newRun(file)
+	tc := toolchainGnuCommon{gccBinary: compiler, flagCache: newFlagCache()}
+	assert.True(t, tc.CheckFlagIsSupported("c", "-Wall"))
+	assert.False(t, tc.CheckFlagIsSupported("c", "-Wbad"))
+	assert.Equal(t, 2, countProbes(t, log))
+	SaveFlagCache()
+
+	// The next run reuses the probes
+	newRun(file)
+	tc = toolchainGnuCommon{gccBinary: compiler, flagCache: newFlagCache()}
+	assert.True(t, tc.CheckFlagIsSupported("c", "-Wall"))
+	assert.False(t, tc.CheckFlagIsSupported("c", "-Wbad"))
+	assert.Equal(t, 2, countProbes(t, log))
+
+	// Unless the compiler has changed since
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(compiler, later, later))
+	newRun(file)
+	tc = toolchainGnuCommon{gccBinary: compiler, flagCache: newFlagCache()}
+	assert.True(t, tc.CheckFlagIsSupported("c", "-Wall"))
+	assert.Equal(t, 3, countProbes(t, log))
+}
+
+func TestSaveFlagCacheFailure(t *testing.T) {
+	dir := t.TempDir()
+	compiler, _ := fakeCompiler(t, dir)
+	file := filepath.Join(dir, "missing", "flag_cache.json")
+	defer newRun("")
+
+	// Failing to write the probes is not fatal
+	newRun(file)
+	tc := toolchainGnuCommon{gccBinary: compiler, flagCache: newFlagCache()}
+	assert.True(t, tc.CheckFlagIsSupported("c", "-Wall"))
+	SaveFlagCache()
+	assert.True(t, persistentFlagCache.changed)
+
+	// Once the directory exists, the probes are written, and no temporary
+	// file is left behind
+	assert.NoError(t, os.Mkdir(filepath.Dir(file), 0755))
+	SaveFlagCache()
+	assert.False(t, persistentFlagCache.changed)
+	entries, err := ioutil.ReadDir(filepath.Dir(file))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "flag_cache.json", entries[0].Name())
+}
@@ -0,0 +1,128 @@
+package toolchain
+
+import (
+	"path/filepath"
+
+	"github.com/ARM-software/bob-build/internal/utils"
+)
+
+// ToolSet lists the tools of a toolchain defined by a `bob_toolchain`
+// module, rather than by the configuration. Empty tools are taken from the
+// toolchain configured for the module's variant.
+type ToolSet struct {
+	Cc      string
+	Cxx     string
+	Ar      string
+	Ld      string // Linker driver, invoked like the C++ compiler
+	Nm      string
+	Ranlib  string
+	Sysroot string
+
+	Dir string // Directory of the module, which a relative Sysroot is in
+}
+
+// IsEmpty returns whether the set replaces none of the tools.
+func (tools ToolSet) IsEmpty() bool {
+	return tools == ToolSet{Dir: tools.Dir}
+}
+
+// toolchainToolSet compiles with the tools of a ToolSet. The tools which
+// the set does not include, like the assembler or objcopy, come from the
+// base toolchain.
+type toolchainToolSet struct {
+	Toolchain
+
+	tools     ToolSet
+	flags     []string // Flags for compiling and linking
+	linker    Linker
+	flagCache *flagSupportedCache
+}
+
+// sysrootLinker adds the sysroot flags of a ToolSet to the base linker.
+type sysrootLinker struct {
+	Linker
+	flags []string
+}
+
+func (l sysrootLinker) GetFlags() []string {
+	return utils.NewStringSlice(l.Linker.GetFlags(), l.flags)
+}
+
+func (tc toolchainToolSet) GetArchiver() (string, []string) {
+	if tc.tools.Ar != "" {
+		return tc.tools.Ar, []string{}
+	}
+	return tc.Toolchain.GetArchiver()
+}
+
+// The flags of the base compilers are specific to them, e.g. a clang
+// `--target`, so they are dropped along with the compiler.
+func (tc toolchainToolSet) GetCCompiler() (string, []string) {
+	if tc.tools.Cc != "" {
+		return tc.tools.Cc, tc.flags
+	}
+	tool, flags := tc.Toolchain.GetCCompiler()
+	return tool, utils.NewStringSlice(flags, tc.flags)
+}
+
+func (tc toolchainToolSet) GetCXXCompiler() (string, []string) {
+	if tc.tools.Cxx != "" {
+		return tc.tools.Cxx, tc.flags
+	}
+	tool, flags := tc.Toolchain.GetCXXCompiler()
+	return tool, utils.NewStringSlice(flags, tc.flags)
+}
+
+func (tc toolchainToolSet) GetLinker() Linker {
+	return tc.linker
+}
+
+func (tc toolchainToolSet) GetNm() (string, []string) {
+	if tc.tools.Nm != "" {
+		return tc.tools.Nm, []string{}
+	}
+	return tc.Toolchain.GetNm()
+}
+
+func (tc toolchainToolSet) GetRanlib() (string, []string) {
+	if tc.tools.Ranlib != "" {
+		return tc.tools.Ranlib, []string{}
+	}
+	return tc.Toolchain.GetRanlib()
+}
+
+func (tc toolchainToolSet) CheckFlagIsSupported(language, flag string) bool {
+	return tc.flagCache.checkFlag(tc, language, flag)
+}
+
+// NewToolchainFromToolSet creates a toolchain using the tools of a set,
+// and those of the base toolchain for the tools the set leaves empty.
+func NewToolchainFromToolSet(tools ToolSet, base Toolchain) Toolchain {
+	tc := toolchainToolSet{
+		Toolchain: base,
+		tools:     tools,
+	}
+
+	if sysroot := tools.Sysroot; sysroot != "" {
+		if !filepath.IsAbs(sysroot) {
+			sysroot = filepath.Join(tools.Dir, sysroot)
+		}
+		tc.flags = append(tc.flags, "--sysroot="+sysroot)
+	}
+
+	// The linker driver defaults to the C++ compiler, as in the
+	// configured toolchains
+	ld := tools.Ld
+	if ld == "" {
+		ld = tools.Cxx
+	}
+	if ld != "" {
+		tc.linker = newCustomLinker(ld, tc.flags, []string{})
+	} else {
+		tc.linker = sysrootLinker{base.GetLinker(), tc.flags}
+	}
+
+	tc.flagCache = newFlagCache()
+
+	return tc
+}
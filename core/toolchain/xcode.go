@@ -9,6 +9,7 @@ type toolchainXcode struct {
 	stripBinary  string
 	otoolBinary  string
 	nmBinary     string
+	gcovBinary   string
 	ranlibBinary string
 	ccBinary     string
 	cxxBinary    string
@@ -79,6 +80,18 @@ func (tc toolchainXcode) CheckFlagIsSupported(language, flag string) bool {
 	return tc.flagCache.checkFlag(tc, language, flag)
 }
 
+func (tc toolchainXcode) GetSanitizerFlags(sanitizers []string) ([]string, []string) {
+	return sanitizerFlags(sanitizers)
+}
+
+func (tc toolchainXcode) GetCoverageFlags() ([]string, []string) {
+	return coverageFlags()
+}
+
+func (tc toolchainXcode) GetGcov() (string, []string) {
+	return tc.gcovBinary, []string{}
+}
+
 func (tc toolchainXcode) Is64BitOnly() bool {
 	return tc.is64BitOnly
 }
@@ -91,6 +104,7 @@ func newToolchainXcodeCommon(props *config.Properties, tgt TgtType) (tc toolchai
 	tc.stripBinary = props.GetString(string(tgt) + "_strip_binary")
 	tc.otoolBinary = props.GetString(string(tgt) + "_otool_binary")
 	tc.nmBinary = props.GetString(string(tgt) + "_nm_binary")
+	tc.gcovBinary = props.GetString(string(tgt) + "_gcov_binary")
 	tc.ranlibBinary = props.GetString(string(tgt) + "_ranlib_binary")
 
 	tc.ccBinary = tc.prefix + props.GetString(string(tgt)+"_clang_cc_binary")
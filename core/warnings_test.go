@@ -0,0 +1,40 @@
+package core_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ARM-software/bob-build/core"
+	"github.com/ARM-software/bob-build/core/config"
+)
+
+func TestRegisterWarningCategory(t *testing.T) {
+	err := core.RegisterWarningCategory(core.WarningCategoryInfo{
+		Name:     "ExampleLintWarning",
+		Category: "example-lint",
+		Message:  "`%s` is not allowed.",
+	})
+	assert.Nil(t, err)
+
+	file := filepath.Join(t.TempDir(), "warnings.jsonl")
+	core.SetupLogger(&config.EnvironmentVariables{
+		LogWarningsFile:   file,
+		LogWarningsFormat: "jsonl",
+		LogWarnings:       "ExampleLintWarning:W",
+	})
+
+	// The filters have been parsed, so it is too late to register more
+	err = core.RegisterWarningCategory(core.WarningCategoryInfo{Name: "LateWarning", Category: "late"})
+	assert.EqualError(t, err, "Warning category 'LateWarning' registered after the logger was set up")
+
+	core.GetLogger().Warn("example-lint", "A/build.bp", "lib", "foo")
+	core.TearDownLogger()
+
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"bp_file":"A/build.bp","bp_module":"lib","action":"warning",`+
+		"\"message\":\"`foo` is not allowed.\",\"category\":\"example-lint\"}\n", string(data))
+}
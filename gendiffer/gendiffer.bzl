@@ -1,6 +1,6 @@
 load("@io_bazel_rules_go//go:def.bzl", "go_test")
 
-def bob_generation_test(name, bob_binary, test_data, size = "small", **kwargs):
+def bob_generation_test(name, bob_binary, test_data, size = "small", backends = ["android", "linux", "android_oot"], **kwargs):
     [
         go_test(
             name = name + "_" + backend,
@@ -24,9 +24,13 @@ def bob_generation_test(name, bob_binary, test_data, size = "small", **kwargs):
                 Label("//gendiffer:bob.android.config.d"),
                 Label("//gendiffer:bob.android_oot.config"),
                 Label("//gendiffer:bob.android_oot.config.json"),
+                Label("//gendiffer:bob.bazel.config"),
+                Label("//gendiffer:bob.bazel.config.json"),
+                Label("//gendiffer:bob.cmake.config"),
+                Label("//gendiffer:bob.cmake.config.json"),
             ],
             testonly = False,
             **kwargs
         )
-        for backend in ["android", "linux", "android_oot"]
+        for backend in backends
     ]
@@ -2,6 +2,7 @@ package gendiffer
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -26,6 +27,9 @@ var (
 	expectedStdoutFilename   = "expectedStdout.txt"
 	expectedStderrFilename   = "expectedStderr.txt"
 	expectedExitCodeFilename = "expectedExitCode.int"
+	compileCommandsFilename  = "compile_commands.json"
+	bobArgsFilename          = "bob_args"
+	bobConfigFilename        = "bob_config.json"
 )
 
 type generationArgs struct {
@@ -52,6 +56,60 @@ func getOverrideablePath(t *testing.T, testDataPathAbsolute, path string) string
 	}
 }
 
+// Returns the arguments given to Bob before its options, such as the `lint`
+// command, listed in the test case's app/bob_args file.
+func getBobArgs(t *testing.T, testDataPathAbsolute string) []string {
+	data, err := os.ReadFile(filepath.Join(testDataPathAbsolute, "app", bobArgsFilename))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		t.Fatalf("Failed to read Bob arguments %s: %v", bobArgsFilename, err)
+	}
+	return strings.Fields(string(data))
+}
+
+// Returns the path of the config JSON used by the test case. The values in
+// its app/bob_config.json file, if any, override those of the backend's
+// config, which is written to a temporary directory with them applied.
+func getConfigJson(t *testing.T, args *generationArgs) string {
+	configJson := getOverrideablePath(t, args.TestDataPathAbsolute, args.ConfigJson)
+	data, err := os.ReadFile(filepath.Join(args.TestDataPathAbsolute, "app", bobConfigFilename))
+	if os.IsNotExist(err) {
+		return configJson
+	} else if err != nil {
+		t.Fatalf("Failed to read config values %s: %v", bobConfigFilename, err)
+	}
+
+	overrides := map[string]interface{}{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		t.Fatalf("Failed to parse config values %s: %v", bobConfigFilename, err)
+	}
+
+	data, err = os.ReadFile(configJson)
+	if err != nil {
+		t.Fatalf("Failed to read config %s: %v", configJson, err)
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Failed to parse config %s: %v", configJson, err)
+	}
+	for key, value := range overrides {
+		config[key] = map[string]interface{}{"ignore": false, "value": value}
+	}
+
+	data, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	configJson = filepath.Join(t.TempDir(), filepath.Base(configJson))
+	if err := os.WriteFile(configJson, data, 0644); err != nil {
+		t.Fatalf("Cannot create file: '%s'", configJson)
+	}
+	// Redact the temporary path from the outputs
+	args.ConfigJson = configJson
+	return configJson
+}
+
 func setCommonEnv(t *testing.T, args *generationArgs) {
 	os.Setenv("TOPNAME", "build.bp")
 	os.Setenv("SRCDIR", args.BobRootAbsolute)
@@ -60,8 +118,11 @@ func setCommonEnv(t *testing.T, args *generationArgs) {
 	os.Setenv("BOB_DIR", args.BobRootAbsolute)
 	os.Setenv("BOB_LOG_WARNINGS_FILE", args.TestDataPathAbsolute+"bob_warnings.csv")
 	os.Setenv("CONFIG_FILE", getOverrideablePath(t, args.TestDataPathAbsolute, args.ConfigFile))
-	os.Setenv("CONFIG_JSON", getOverrideablePath(t, args.TestDataPathAbsolute, args.ConfigJson))
+	os.Setenv("CONFIG_JSON", getConfigJson(t, args))
 	os.Setenv("BOB_LINK_PARALLELISM", "1")
+	if args.BackendType == "linux" {
+		os.Setenv("BOB_COMPILE_COMMANDS_FILE", args.BobRootAbsolute+"/"+compileCommandsFilename)
+	}
 }
 
 func diff(filename string, a []byte, b []byte) ([]byte, error) {
@@ -120,6 +181,10 @@ func redact(args *generationArgs, data []byte) []byte {
 	data = regexp.MustCompile(args.BobRootAbsolute).ReplaceAll(data, []byte("redacted"))
 	data = regexp.MustCompile(args.ConfigFile).ReplaceAll(data, []byte("%REDACTED_CONFIG_FILE%"))
 	data = regexp.MustCompile(args.ConfigJson).ReplaceAll(data, []byte("%REDACTED_CONFIG_JSON%"))
+	if wd, err := os.Getwd(); err == nil {
+		// Bob's working directory, recorded in compile_commands.json
+		data = regexp.MustCompile(regexp.QuoteMeta(wd)).ReplaceAll(data, []byte("%REDACTED_WORKDIR%"))
+	}
 	data = regexp.MustCompile("(?m)^(# Defined: .*/?build.bp:)[0-9]+:[0-9]+").ReplaceAll(data, redacted)
 	return data
 }
@@ -134,6 +199,9 @@ func checkFileContents(args *generationArgs, filename string, data []byte) error
 		// if no existing file, create it
 		if _, fErr := os.Stat(absolute); errors.Is(fErr, os.ErrNotExist) {
 			outFile := path.Join(args.BuildWorkspaceDirectory, args.TestDataPathRelative, "out", args.BackendType, filename)
+			if err := os.MkdirAll(path.Dir(outFile), 0755); err != nil {
+				return err
+			}
 			f, err := os.Create(outFile)
 			if err != nil {
 				return err
@@ -183,6 +251,38 @@ var generated = map[string]string{
 	"android":     "Android.bp",
 	"linux":       "build.ninja",
 	"android_oot": "build.ninja",
+	"bazel":       "BUILD.bazel",
+	"cmake":       "CMakeLists.txt",
+}
+
+// Returns the files generated by the backend, relative to the app
+// directory. The Bazel and CMake backends write a file for each
+// directory.
+func generatedFiles(args *generationArgs) []string {
+	filename := generated[args.BackendType]
+	if args.BackendType != "bazel" && args.BackendType != "cmake" {
+		files := []string{filename}
+		// The compilation database is only checked where a snapshot exists
+		snapshot := path.Join(args.TestDataPathAbsolute, "out", args.BackendType, compileCommandsFilename+".out")
+		if _, err := os.Stat(snapshot); err == nil {
+			files = append(files, compileCommandsFilename)
+		}
+		return files
+	}
+
+	files := []string{}
+	filepath.Walk(args.BobRootAbsolute, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && info.Name() == filename {
+			rel, _ := filepath.Rel(args.BobRootAbsolute, p)
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if len(files) == 0 {
+		// Report the missing root package
+		files = append(files, filename)
+	}
+	return files
 }
 
 func singleBobGenerationTest(t *testing.T, args *generationArgs) {
@@ -191,7 +291,8 @@ func singleBobGenerationTest(t *testing.T, args *generationArgs) {
 	expectedExitCode := getFileInt(t, path.Join(args.TestDataPathAbsolute, "out", args.BackendType, expectedExitCodeFilename))
 	var stdOut bytes.Buffer
 	var stdErr bytes.Buffer
-	runCmd := exec.Command(args.BobBinaryPath, "-l", args.BobRootAbsolute+"/bplist", "-b", args.BobRootAbsolute, "-n", args.BobRootAbsolute, "-d", args.BobRootAbsolute+"/ninja.build.d", "-o", args.BobRootAbsolute+"/build.ninja", args.BobRootAbsolute+"/build.bp")
+	bobArgs := getBobArgs(t, args.TestDataPathAbsolute)
+	runCmd := exec.Command(args.BobBinaryPath, append(bobArgs, "-l", args.BobRootAbsolute+"/bplist", "-b", args.BobRootAbsolute, "-n", args.BobRootAbsolute, "-d", args.BobRootAbsolute+"/ninja.build.d", "-o", args.BobRootAbsolute+"/build.ninja", args.BobRootAbsolute+"/build.bp")...)
 	runCmd.Stdout = &stdOut
 	runCmd.Stderr = &stdErr
 	err := runCmd.Run()
@@ -238,9 +339,15 @@ func singleBobGenerationTest(t *testing.T, args *generationArgs) {
 	if err := checkFileContents(args, expectedStderrFilename, stdErr.Bytes()); err != nil {
 		errs = append(errs, err)
 	}
-	if err := checkFile(args, generated[args.BackendType]); err != nil {
-		if expectedExitCode == 0 || !os.IsNotExist(err) {
-			errs = append(errs, err)
+	// `bob lint` only reports on the build definitions
+	lint := len(bobArgs) > 0 && bobArgs[0] == "lint"
+	if !lint {
+		for _, filename := range generatedFiles(args) {
+			if err := checkFile(args, filename); err != nil {
+				if expectedExitCode == 0 || !os.IsNotExist(err) {
+					errs = append(errs, err)
+				}
+			}
 		}
 	}
 	for _, err := range errs {
@@ -0,0 +1,318 @@
+// Package archive writes reproducible tar and Debian archives of installed
+// files, without depending on host tools.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// An Entry is a file or symlink to add to an archive.
+type Entry struct {
+	// Path in the archive, relative to its root
+	Name string
+	// Path of the file to read
+	Source string
+	// Permissions of the file, or -1 to use those of the source
+	Mode  int64
+	Owner string
+	Group string
+}
+
+// A header, together with the file or the data providing its content
+type member struct {
+	hdr     *tar.Header
+	source  string
+	content []byte
+}
+
+func ownerOrRoot(name string) string {
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// Returns the header of a directory, given its path relative to the root.
+func dirHeader(dir string, mtime time.Time) *tar.Header {
+	name := "./"
+	if dir != "." {
+		name += dir + "/"
+	}
+	return &tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name,
+		Mode:     0755,
+		ModTime:  mtime,
+		Uname:    "root",
+		Gname:    "root",
+	}
+}
+
+// Returns the tar members for the entries, including the directories
+// containing them. Members are sorted by name, and all use the same mtime,
+// so that the archive only depends on the contents of the files.
+func members(entries []Entry, mtime time.Time) ([]member, error) {
+	ms := map[string]member{"./": {hdr: dirHeader(".", mtime)}}
+
+	for _, e := range entries {
+		clean := path.Clean(e.Name)
+		name := "./" + clean
+		fi, err := os.Lstat(e.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		hdr := &tar.Header{
+			Name:    name,
+			ModTime: mtime,
+			Uname:   ownerOrRoot(e.Owner),
+			Gname:   ownerOrRoot(e.Group),
+		}
+		source := ""
+
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(e.Source)
+			if err != nil {
+				return nil, err
+			}
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = target
+			hdr.Mode = 0777
+		} else if fi.Mode().IsRegular() {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = fi.Size()
+			hdr.Mode = int64(fi.Mode().Perm())
+			if e.Mode >= 0 {
+				hdr.Mode = e.Mode
+			}
+			source = e.Source
+		} else {
+			return nil, fmt.Errorf("%s is not a regular file or a symlink", e.Source)
+		}
+
+		if _, ok := ms[name]; ok {
+			return nil, fmt.Errorf("%s is installed more than once", e.Name)
+		}
+		ms[name] = member{hdr: hdr, source: source}
+
+		for dir := path.Dir(clean); dir != "."; dir = path.Dir(dir) {
+			hdr := dirHeader(dir, mtime)
+			if _, ok := ms[hdr.Name]; ok {
+				break
+			}
+			ms[hdr.Name] = member{hdr: hdr}
+		}
+	}
+
+	sorted := []member{}
+	for _, m := range ms {
+		sorted = append(sorted, m)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].hdr.Name < sorted[j].hdr.Name
+	})
+	return sorted, nil
+}
+
+// Writes a gzip-compressed tar archive of the members. The gzip header
+// carries no name or timestamp.
+func writeTarGz(w io.Writer, ms []member) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+
+	for _, m := range ms {
+		if err := tw.WriteHeader(m.hdr); err != nil {
+			return err
+		}
+		if m.content != nil {
+			if _, err := tw.Write(m.content); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.source == "" {
+			continue
+		}
+		f, err := os.Open(m.source)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// WriteTar writes a reproducible `.tar.gz` archive of the entries, with
+// every member's mtime set to `mtime`.
+func WriteTar(w io.Writer, entries []Entry, mtime time.Time) error {
+	ms, err := members(entries, mtime)
+	if err != nil {
+		return err
+	}
+	return writeTarGz(w, ms)
+}
+
+// Control holds the fields of a Debian control file.
+type Control struct {
+	Package      string
+	Version      string
+	Architecture string
+	Maintainer   string
+	Section      string
+	Priority     string
+	Depends      []string
+	// The first line is the synopsis, the remaining lines the extended
+	// description.
+	Description string
+}
+
+// Formats the control file. `installedSize` is in bytes.
+func (c Control) format(installedSize int64) string {
+	var b strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	field("Package", c.Package)
+	field("Version", c.Version)
+	field("Architecture", c.Architecture)
+	field("Maintainer", c.Maintainer)
+	field("Installed-Size", fmt.Sprint((installedSize+1023)/1024))
+	field("Depends", strings.Join(c.Depends, ", "))
+	field("Section", c.Section)
+	field("Priority", c.Priority)
+
+	lines := strings.Split(strings.TrimSpace(c.Description), "\n")
+	field("Description", lines[0])
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			line = "."
+		}
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+
+	return b.String()
+}
+
+// Writes a member of an ar archive, padded to an even size.
+func writeArMember(w io.Writer, name string, mtime time.Time, data []byte) error {
+	hdr := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8o%-10d`\n", name, mtime.Unix(), 0, 0, 0100644, len(data))
+	if _, err := io.WriteString(w, hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 != 0 {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
+
+// Returns the checksums of the regular files, in the format of the
+// `md5sums` control file.
+func md5sums(ms []member) (string, error) {
+	var b strings.Builder
+	for _, m := range ms {
+		if m.source == "" {
+			continue
+		}
+		f, err := os.Open(m.source)
+		if err != nil {
+			return "", err
+		}
+		h := md5.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%x  %s\n", h.Sum(nil), strings.TrimPrefix(m.hdr.Name, "./"))
+	}
+	return b.String(), nil
+}
+
+// WriteDeb writes a reproducible Debian binary package of the entries.
+func WriteDeb(w io.Writer, ctrl Control, entries []Entry, mtime time.Time) error {
+	data, err := members(entries, mtime)
+	if err != nil {
+		return err
+	}
+
+	var installedSize int64
+	for _, m := range data {
+		installedSize += m.hdr.Size
+	}
+
+	sums, err := md5sums(data)
+	if err != nil {
+		return err
+	}
+
+	control := []member{{hdr: dirHeader(".", mtime)}}
+	for _, f := range []struct {
+		name    string
+		content string
+	}{
+		{"./control", ctrl.format(installedSize)},
+		{"./md5sums", sums},
+	} {
+		control = append(control, member{
+			hdr: &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     f.name,
+				Mode:     0644,
+				Size:     int64(len(f.content)),
+				ModTime:  mtime,
+				Uname:    "root",
+				Gname:    "root",
+			},
+			content: []byte(f.content),
+		})
+	}
+
+	var controlTarGz bytes.Buffer
+	if err := writeTarGz(&controlTarGz, control); err != nil {
+		return err
+	}
+
+	var dataTarGz bytes.Buffer
+	if err := writeTarGz(&dataTarGz, data); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+	if err := writeArMember(w, "debian-binary", mtime, []byte("2.0\n")); err != nil {
+		return err
+	}
+	if err := writeArMember(w, "control.tar.gz", mtime, controlTarGz.Bytes()); err != nil {
+		return err
+	}
+	return writeArMember(w, "data.tar.gz", mtime, dataTarGz.Bytes())
+}
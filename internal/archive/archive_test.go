@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestTree(t *testing.T) []Entry {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "usr", "bin")
+	assert.NoError(t, os.MkdirAll(bin, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "app"), []byte("binary\n"), 0600))
+	assert.NoError(t, os.Symlink("app", filepath.Join(bin, "app-latest")))
+
+	return []Entry{
+		{Name: "usr/bin/app-latest", Source: filepath.Join(bin, "app-latest"), Mode: -1},
+		{Name: "usr/bin/app", Source: filepath.Join(bin, "app"), Mode: 0755, Group: "staff"},
+	}
+}
+
+func readTarGz(t *testing.T, data []byte) (hdrs []*tar.Header, contents map[string]string) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	contents = map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		b, err := io.ReadAll(tr)
+		assert.NoError(t, err)
+		hdrs = append(hdrs, hdr)
+		contents[hdr.Name] = string(b)
+	}
+	return
+}
+
+func Test_WriteTar(t *testing.T) {
+	entries := writeTestTree(t)
+	mtime := time.Unix(1700000000, 0)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTar(&buf, entries, mtime))
+	hdrs, contents := readTarGz(t, buf.Bytes())
+
+	names := []string{}
+	for _, hdr := range hdrs {
+		names = append(names, hdr.Name)
+		assert.True(t, hdr.ModTime.Equal(mtime), hdr.Name)
+	}
+	assert.Equal(t, []string{"./", "./usr/", "./usr/bin/", "./usr/bin/app", "./usr/bin/app-latest"}, names)
+
+	app := hdrs[3]
+	assert.Equal(t, int64(0755), app.Mode)
+	assert.Equal(t, "root", app.Uname)
+	assert.Equal(t, "staff", app.Gname)
+	assert.Equal(t, "binary\n", contents["./usr/bin/app"])
+
+	link := hdrs[4]
+	assert.Equal(t, byte(tar.TypeSymlink), link.Typeflag)
+	assert.Equal(t, "app", link.Linkname)
+
+	// The archive only depends on the files
+	var again bytes.Buffer
+	assert.NoError(t, WriteTar(&again, entries, mtime))
+	assert.Equal(t, buf.Bytes(), again.Bytes())
+}
+
+func Test_WriteTarDuplicate(t *testing.T) {
+	entries := writeTestTree(t)
+	entries = append(entries, entries[1])
+
+	var buf bytes.Buffer
+	assert.Error(t, WriteTar(&buf, entries, time.Unix(0, 0)))
+}
+
+// Splits an ar archive into its members
+func readAr(t *testing.T, data []byte) (names []string, members map[string][]byte) {
+	assert.True(t, bytes.HasPrefix(data, []byte("!<arch>\n")))
+	data = data[8:]
+	members = map[string][]byte{}
+	for len(data) > 0 {
+		hdr := string(data[:60])
+		assert.Equal(t, "`\n", hdr[58:60])
+		name := strings.TrimSpace(hdr[:16])
+		size, err := strconv.Atoi(strings.TrimSpace(hdr[48:58]))
+		assert.NoError(t, err)
+		names = append(names, name)
+		members[name] = data[60 : 60+size]
+		data = data[60+size+size%2:]
+	}
+	return
+}
+
+func Test_WriteDeb(t *testing.T) {
+	entries := writeTestTree(t)
+	control := Control{
+		Package:      "app",
+		Version:      "1.0-1",
+		Architecture: "arm64",
+		Maintainer:   "Jo Bloggs <jo@example.com>",
+		Depends:      []string{"libc6", "libfoo (>= 2)"},
+		Description:  "An application\nIt does things.\n\nWell.",
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDeb(&buf, control, entries, time.Unix(0, 0)))
+	names, members := readAr(t, buf.Bytes())
+	assert.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.gz"}, names)
+	assert.Equal(t, "2.0\n", string(members["debian-binary"]))
+
+	_, contents := readTarGz(t, members["control.tar.gz"])
+	assert.Equal(t, "Package: app\n"+
+		"Version: 1.0-1\n"+
+		"Architecture: arm64\n"+
+		"Maintainer: Jo Bloggs <jo@example.com>\n"+
+		"Installed-Size: 1\n"+
+		"Depends: libc6, libfoo (>= 2)\n"+
+		"Description: An application\n"+
+		" It does things.\n"+
+		" .\n"+
+		" Well.\n", contents["./control"])
+	assert.Equal(t, "1e59dd43bb6eb0ff21785aa255074fcb  usr/bin/app\n", contents["./md5sums"])
+
+	_, data := readTarGz(t, members["data.tar.gz"])
+	assert.Equal(t, "binary\n", data["./usr/bin/app"])
+}
@@ -0,0 +1,381 @@
+package bzlwriter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Implement types and helpers to record the rules that we want to
+// write into BUILD.bazel files.
+//
+// This mirrors bpwriter, but renders Starlark in the layout used by
+// buildifier. Rules are calls taking keyword arguments, whose values
+// can be strings, booleans, integers, string lists, string dictionaries
+// or a call to glob().
+
+func indentString(depth int) string {
+	return strings.Repeat(" ", depth*4)
+}
+
+var bzlEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+
+func Escape(s string) string {
+	return bzlEscaper.Replace(s)
+}
+
+func quote(s string) string {
+	return "\"" + Escape(s) + "\""
+}
+
+// A Starlark expression, rendered at the indentation of the line it
+// starts on.
+type value interface {
+	render(depth int) string
+}
+
+type literal string
+
+func (l literal) render(depth int) string {
+	return string(l)
+}
+
+type list []string
+
+func (l list) render(depth int) string {
+	switch len(l) {
+	case 0:
+		return "[]"
+	case 1:
+		return "[" + quote(l[0]) + "]"
+	}
+
+	// Put each entry on a new line, indented
+	s := "[\n"
+	indent := indentString(depth + 1)
+	for _, v := range l {
+		s += indent + quote(v) + ",\n"
+	}
+	return s + indentString(depth) + "]"
+}
+
+type dict map[string]string
+
+func (d dict) render(depth int) string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if len(keys) == 1 {
+		return "{" + quote(keys[0]) + ": " + quote(d[keys[0]]) + "}"
+	}
+
+	s := "{\n"
+	indent := indentString(depth + 1)
+	for _, k := range keys {
+		s += indent + quote(k) + ": " + quote(d[k]) + ",\n"
+	}
+	return s + indentString(depth) + "}"
+}
+
+// Argument of a call. Positional arguments have no key.
+type argument struct {
+	key   string
+	value value
+}
+
+func (a argument) render(depth int) string {
+	if a.key == "" {
+		return a.value.render(depth)
+	}
+	return a.key + " = " + a.value.render(depth)
+}
+
+// A function call. Calls with a single argument are kept on one line,
+// otherwise each argument is put on its own line.
+type call struct {
+	function string
+	args     []argument
+}
+
+func (c *call) render(depth int) string {
+	if len(c.args) == 1 {
+		return c.function + "(" + c.args[0].render(depth) + ")"
+	}
+
+	s := c.function + "(\n"
+	indent := indentString(depth + 1)
+	for _, a := range c.args {
+		s += indent + a.render(depth+1) + ",\n"
+	}
+	return s + indentString(depth) + ")"
+}
+
+func (c *call) add(key string, v value) {
+	c.args = append(c.args, argument{key, v})
+}
+
+// Arguments of a rule, or of the package() function
+type Rule interface {
+	AddString(name, value string)
+	AddOptionalString(name string, value *string)
+	AddBool(name string, value bool)
+	AddOptionalBool(name string, value *bool)
+	AddInt(name string, value int)
+	AddStringList(name string, list []string)
+	AddStringDict(name string, dict map[string]string)
+	AddGlob(name string, include, exclude []string, allowEmpty *bool)
+}
+
+// No locking for rules, as the creation of each rule is done in a
+// single thread.
+type rule struct {
+	call
+}
+
+var _ Rule = (*rule)(nil)
+
+// Add a string argument to the rule
+func (r *rule) AddString(name, value string) {
+	r.add(name, literal(quote(value)))
+}
+
+// Add a string argument to the rule if the string exists
+func (r *rule) AddOptionalString(name string, value *string) {
+	if value != nil {
+		r.AddString(name, *value)
+	}
+}
+
+// Add a boolean argument to the rule
+func (r *rule) AddBool(name string, value bool) {
+	if value {
+		r.add(name, literal("True"))
+	} else {
+		r.add(name, literal("False"))
+	}
+}
+
+// Add a boolean argument to the rule, leaving it unset if no value was
+// explicitly provided.
+func (r *rule) AddOptionalBool(name string, value *bool) {
+	if value != nil {
+		r.AddBool(name, *value)
+	}
+}
+
+func (r *rule) AddInt(name string, value int) {
+	r.add(name, literal(strconv.Itoa(value)))
+}
+
+// Add a string list argument to the rule, leaving it unset if the list
+// is empty.
+func (r *rule) AddStringList(name string, l []string) {
+	if len(l) > 0 {
+		r.add(name, list(append([]string(nil), l...)))
+	}
+}
+
+// Add a string dictionary argument to the rule, leaving it unset if the
+// dictionary is empty.
+func (r *rule) AddStringDict(name string, d map[string]string) {
+	if len(d) > 0 {
+		cp := dict{}
+		for k, v := range d {
+			cp[k] = v
+		}
+		r.add(name, cp)
+	}
+}
+
+// Add an argument whose value is the result of glob(), matching the
+// `include` patterns but not the `exclude` ones.
+func (r *rule) AddGlob(name string, include, exclude []string, allowEmpty *bool) {
+	g := &rule{call{function: "glob"}}
+	g.add("", list(append([]string(nil), include...)))
+	g.AddStringList("exclude", exclude)
+	g.AddOptionalBool("allow_empty", allowEmpty)
+	r.add(name, &g.call)
+}
+
+// Content for a BUILD.bazel file
+type File interface {
+	// Load symbols from a .bzl file
+	Load(label string, symbols ...string)
+	// Arguments of the package() function, which is only written if
+	// any are set.
+	Package() Rule
+	// Make source files of the package visible to other packages
+	ExportFiles(files ...string)
+	NewRule(kind, name string) (Rule, error)
+	Render(b *strings.Builder)
+}
+
+type file struct {
+	sync.Mutex
+	loads   map[string]map[string]bool
+	pkg     *rule
+	exports map[string]bool
+	rules   map[string]*rule
+}
+
+var _ File = (*file)(nil)
+
+func (f *file) Load(label string, symbols ...string) {
+	f.Lock()
+	defer f.Unlock()
+
+	if _, ok := f.loads[label]; !ok {
+		f.loads[label] = map[string]bool{}
+	}
+	for _, s := range symbols {
+		f.loads[label][s] = true
+	}
+}
+
+func (f *file) Package() Rule {
+	return f.pkg
+}
+
+func (f *file) ExportFiles(files ...string) {
+	// Exports are added while generating the rules of other packages,
+	// which may happen in parallel.
+	f.Lock()
+	defer f.Unlock()
+
+	for _, s := range files {
+		f.exports[s] = true
+	}
+}
+
+// Create a rule
+func (f *file) NewRule(kind, name string) (Rule, error) {
+	r := &rule{call{function: kind}}
+	r.AddString("name", name)
+
+	// Lock the addition to ensure parallel build actions can add
+	// rules to the file.
+	f.Lock()
+	defer f.Unlock()
+
+	if _, dup := f.rules[name]; dup {
+		return nil, fmt.Errorf("Duplicate rule name (%s)", name)
+	}
+	f.rules[name] = r
+
+	return r, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render the file, with the load statements first, followed by the
+// package() function, the exported files and the rules, sorted by name.
+func (f *file) Render(b *strings.Builder) {
+	statements := []string{}
+
+	if len(f.loads) > 0 {
+		labels := make([]string, 0, len(f.loads))
+		for label := range f.loads {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		// Load statements are always kept on one line
+		loads := ""
+		for _, label := range labels {
+			args := []string{quote(label)}
+			for _, s := range sortedKeys(f.loads[label]) {
+				args = append(args, quote(s))
+			}
+			loads += "load(" + strings.Join(args, ", ") + ")\n"
+		}
+		statements = append(statements, loads)
+	}
+
+	if len(f.pkg.args) > 0 {
+		statements = append(statements, f.pkg.render(0)+"\n")
+	}
+
+	if len(f.exports) > 0 {
+		e := &call{function: "exports_files"}
+		e.add("", list(sortedKeys(f.exports)))
+		statements = append(statements, e.render(0)+"\n")
+	}
+
+	names := make([]string, 0, len(f.rules))
+	for name := range f.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		statements = append(statements, f.rules[name].render(0)+"\n")
+	}
+
+	b.WriteString(strings.Join(statements, "\n"))
+}
+
+func FileFactory() File {
+	f := file{}
+	f.loads = map[string]map[string]bool{}
+	f.pkg = &rule{call{function: "package"}}
+	f.exports = map[string]bool{}
+	f.rules = map[string]*rule{}
+	return &f
+}
+
+// The BUILD.bazel files of a source tree, one for each package
+type Tree interface {
+	// Returns the file of a package, creating it if needed. The root
+	// package is "".
+	File(pkg string) File
+	// Returns the packages with a file, sorted.
+	Packages() []string
+}
+
+type tree struct {
+	sync.Mutex
+	files map[string]File
+}
+
+var _ Tree = (*tree)(nil)
+
+func (t *tree) File(pkg string) File {
+	t.Lock()
+	defer t.Unlock()
+
+	f, ok := t.files[pkg]
+	if !ok {
+		f = FileFactory()
+		t.files[pkg] = f
+	}
+	return f
+}
+
+func (t *tree) Packages() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	pkgs := make([]string, 0, len(t.files))
+	for pkg := range t.files {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+func TreeFactory() Tree {
+	t := tree{}
+	t.files = map[string]File{}
+	return &t
+}
@@ -0,0 +1,127 @@
+package bzlwriter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func render(f File) string {
+	sb := &strings.Builder{}
+	f.Render(sb)
+	return sb.String()
+}
+
+func TestRule(t *testing.T) {
+	f := FileFactory()
+	f.Load("@rules_cc//cc:defs.bzl", "cc_library")
+
+	r, err := f.NewRule("cc_library", "libfoo")
+	assert.Nil(t, err)
+	r.AddStringList("srcs", []string{"foo.c"})
+	r.AddStringList("hdrs", []string{"foo.h", "include/foo_internal.h"})
+	r.AddStringList("deps", []string{})
+	r.AddString("strip_include_prefix", "include")
+	r.AddBool("alwayslink", true)
+
+	assert.Equal(t, `load("@rules_cc//cc:defs.bzl", "cc_library")
+
+cc_library(
+    name = "libfoo",
+    srcs = ["foo.c"],
+    hdrs = [
+        "foo.h",
+        "include/foo_internal.h",
+    ],
+    strip_include_prefix = "include",
+    alwayslink = True,
+)
+`, render(f))
+}
+
+func TestDuplicateRule(t *testing.T) {
+	f := FileFactory()
+
+	_, err := f.NewRule("filegroup", "files")
+	assert.Nil(t, err)
+	_, err = f.NewRule("genrule", "files")
+	assert.NotNil(t, err)
+}
+
+func TestLayout(t *testing.T) {
+	f := FileFactory()
+	f.Load("@rules_cc//cc:defs.bzl", "cc_test", "cc_binary")
+	f.Load("@rules_cc//cc:defs.bzl", "cc_binary")
+	f.Package().AddStringList("default_visibility", []string{"//visibility:public"})
+	f.ExportFiles("b.h", "a.h")
+
+	r, _ := f.NewRule("cc_test", "test")
+	r.AddStringDict("env", map[string]string{"B": "2", "A": "\"1\""})
+	r.AddInt("shard_count", 2)
+
+	r, _ = f.NewRule("cc_binary", "app")
+	r.AddStringDict("env", map[string]string{"A": "1"})
+
+	assert.Equal(t, `load("@rules_cc//cc:defs.bzl", "cc_binary", "cc_test")
+
+package(default_visibility = ["//visibility:public"])
+
+exports_files([
+    "a.h",
+    "b.h",
+])
+
+cc_binary(
+    name = "app",
+    env = {"A": "1"},
+)
+
+cc_test(
+    name = "test",
+    env = {
+        "A": "\"1\"",
+        "B": "2",
+    },
+    shard_count = 2,
+)
+`, render(f))
+}
+
+func TestGlob(t *testing.T) {
+	f := FileFactory()
+	allowEmpty := false
+
+	r, _ := f.NewRule("filegroup", "all")
+	r.AddGlob("srcs", []string{"**/*.c"}, nil, nil)
+
+	r, _ = f.NewRule("filegroup", "some")
+	r.AddGlob("srcs", []string{"src/*.c", "src/*.h"}, []string{"src/test.c"}, &allowEmpty)
+
+	assert.Equal(t, `filegroup(
+    name = "all",
+    srcs = glob(["**/*.c"]),
+)
+
+filegroup(
+    name = "some",
+    srcs = glob(
+        [
+            "src/*.c",
+            "src/*.h",
+        ],
+        exclude = ["src/test.c"],
+        allow_empty = False,
+    ),
+)
+`, render(f))
+}
+
+func TestTree(t *testing.T) {
+	tr := TreeFactory()
+
+	a := tr.File("a")
+	tr.File("")
+	assert.Equal(t, a, tr.File("a"))
+	assert.Equal(t, []string{"", "a"}, tr.Packages())
+}
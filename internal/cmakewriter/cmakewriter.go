@@ -0,0 +1,253 @@
+package cmakewriter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Implement types and helpers to record the commands that we want to
+// write into CMakeLists.txt files.
+//
+// Commands are grouped into blocks, one for each exported module, which
+// are written sorted by name so that the output does not depend on the
+// order modules are visited in. Commands which fit are kept on one line;
+// otherwise the leading arguments stay on the first line, and each
+// following argument, or keyword and its values, goes on its own line.
+
+const lineLength = 80
+
+func indentString(depth int) string {
+	return strings.Repeat(" ", depth*4)
+}
+
+var escaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+
+// Quote an argument if needed. Variable references are kept, so that
+// CMake expands them.
+func Quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n;\"()#\\") {
+		return "\"" + escaper.Replace(s) + "\""
+	}
+	return s
+}
+
+// An argument of a command. Keywords are followed by their values.
+type argument struct {
+	keyword string
+	values  []string
+}
+
+func (a argument) flatten() (args []string) {
+	if a.keyword != "" {
+		args = append(args, a.keyword)
+	}
+	for _, v := range a.values {
+		args = append(args, Quote(v))
+	}
+	return
+}
+
+type Command interface {
+	// Add arguments, each written on its own line if the command
+	// does not fit on one line.
+	AddArgs(args ...string)
+	// Add a keyword followed by its values. Keywords without values
+	// are options.
+	AddKeyword(keyword string, values ...string)
+}
+
+// No locking for commands, as the creation of each block is done in a
+// single thread.
+type command struct {
+	name string
+	head []string
+	args []argument
+}
+
+var _ Command = (*command)(nil)
+
+func (c *command) AddArgs(args ...string) {
+	for _, a := range args {
+		c.args = append(c.args, argument{values: []string{a}})
+	}
+}
+
+func (c *command) AddKeyword(keyword string, values ...string) {
+	c.args = append(c.args, argument{keyword, append([]string(nil), values...)})
+}
+
+func (c *command) render() string {
+	head := []string{}
+	for _, h := range c.head {
+		head = append(head, Quote(h))
+	}
+
+	all := append([]string(nil), head...)
+	for _, a := range c.args {
+		all = append(all, a.flatten()...)
+	}
+
+	line := c.name + "(" + strings.Join(all, " ") + ")"
+	if len(line) <= lineLength && !strings.Contains(line, "\n") {
+		return line
+	}
+
+	s := c.name + "(" + strings.Join(head, " ") + "\n"
+	indent := indentString(1)
+	for _, a := range c.args {
+		args := a.flatten()
+		if a.keyword == "" || len(indent)+len(strings.Join(args, " ")) <= lineLength {
+			s += indent + strings.Join(args, " ") + "\n"
+			continue
+		}
+
+		// Put each value on a new line, indented below the keyword
+		s += indent + a.keyword + "\n"
+		for _, v := range args[1:] {
+			s += indentString(2) + v + "\n"
+		}
+	}
+	return s + ")"
+}
+
+// Commands written together, in the order they were added
+type Block interface {
+	// Add a command. Its `head` arguments are always written on the
+	// first line.
+	NewCommand(name string, head ...string) Command
+}
+
+type block struct {
+	commands []*command
+}
+
+var _ Block = (*block)(nil)
+
+func (b *block) NewCommand(name string, head ...string) Command {
+	c := &command{name: name, head: append([]string(nil), head...)}
+	b.commands = append(b.commands, c)
+	return c
+}
+
+func (b *block) render() string {
+	s := ""
+	for _, c := range b.commands {
+		s += c.render() + "\n"
+	}
+	return s
+}
+
+// Content for a CMakeLists.txt file
+type File interface {
+	// Commands written at the top of the file
+	Header() Block
+	// Create the block of commands of a module
+	NewBlock(name string) (Block, error)
+	Render(b *strings.Builder)
+}
+
+type file struct {
+	sync.Mutex
+	header *block
+	blocks map[string]*block
+}
+
+var _ File = (*file)(nil)
+
+func (f *file) Header() Block {
+	return f.header
+}
+
+func (f *file) NewBlock(name string) (Block, error) {
+	b := &block{}
+
+	// Lock the addition to ensure parallel build actions can add
+	// blocks to the file.
+	f.Lock()
+	defer f.Unlock()
+
+	if _, dup := f.blocks[name]; dup {
+		return nil, fmt.Errorf("Duplicate block name (%s)", name)
+	}
+	f.blocks[name] = b
+
+	return b, nil
+}
+
+// Render the file, with the header first, followed by the blocks,
+// sorted by name. Blocks are separated by blank lines.
+func (f *file) Render(b *strings.Builder) {
+	sections := []string{}
+
+	if len(f.header.commands) > 0 {
+		sections = append(sections, f.header.render())
+	}
+
+	names := make([]string, 0, len(f.blocks))
+	for name := range f.blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(f.blocks[name].commands) > 0 {
+			sections = append(sections, f.blocks[name].render())
+		}
+	}
+
+	b.WriteString(strings.Join(sections, "\n"))
+}
+
+func FileFactory() File {
+	f := file{}
+	f.header = &block{}
+	f.blocks = map[string]*block{}
+	return &f
+}
+
+// The CMakeLists.txt files of a source tree, one for each directory
+type Tree interface {
+	// Returns the file of a directory, creating it if needed. The root
+	// directory is "".
+	File(dir string) File
+	// Returns the directories with a file, sorted.
+	Dirs() []string
+}
+
+type tree struct {
+	sync.Mutex
+	files map[string]File
+}
+
+var _ Tree = (*tree)(nil)
+
+func (t *tree) File(dir string) File {
+	t.Lock()
+	defer t.Unlock()
+
+	f, ok := t.files[dir]
+	if !ok {
+		f = FileFactory()
+		t.files[dir] = f
+	}
+	return f
+}
+
+func (t *tree) Dirs() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	dirs := make([]string, 0, len(t.files))
+	for dir := range t.files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func TreeFactory() Tree {
+	t := tree{}
+	t.files = map[string]File{}
+	return &t
+}
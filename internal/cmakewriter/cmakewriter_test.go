@@ -0,0 +1,90 @@
+package cmakewriter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func render(f File) string {
+	sb := &strings.Builder{}
+	f.Render(sb)
+	return sb.String()
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "libfoo", Quote("libfoo"))
+	assert.Equal(t, "${PROJECT_SOURCE_DIR}/foo.c", Quote("${PROJECT_SOURCE_DIR}/foo.c"))
+	assert.Equal(t, "\"\"", Quote(""))
+	assert.Equal(t, "\"a b\"", Quote("a b"))
+	assert.Equal(t, "\"-DSTR=\\\"x\\\"\"", Quote("-DSTR=\"x\""))
+}
+
+func TestCommand(t *testing.T) {
+	f := FileFactory()
+
+	b, err := f.NewBlock("libfoo")
+	assert.Nil(t, err)
+	c := b.NewCommand("add_library", "libfoo", "STATIC")
+	c.AddArgs("${PROJECT_SOURCE_DIR}/src/foo.c", "${PROJECT_SOURCE_DIR}/src/foo_internal.c")
+	b.NewCommand("target_link_libraries", "libfoo").AddKeyword("PRIVATE", "libbar")
+
+	assert.Equal(t, `add_library(libfoo STATIC
+    ${PROJECT_SOURCE_DIR}/src/foo.c
+    ${PROJECT_SOURCE_DIR}/src/foo_internal.c
+)
+target_link_libraries(libfoo PRIVATE libbar)
+`, render(f))
+}
+
+func TestKeywords(t *testing.T) {
+	f := FileFactory()
+
+	b, _ := f.NewBlock("gen")
+	c := b.NewCommand("add_custom_command")
+	c.AddKeyword("OUTPUT", "${PROJECT_BINARY_DIR}/gen/gen/first.c", "${PROJECT_BINARY_DIR}/gen/gen/second.c")
+	c.AddKeyword("COMMAND", "sh", "-c", "gen.py --out a.c")
+	c.AddKeyword("VERBATIM")
+
+	assert.Equal(t, `add_custom_command(
+    OUTPUT
+        ${PROJECT_BINARY_DIR}/gen/gen/first.c
+        ${PROJECT_BINARY_DIR}/gen/gen/second.c
+    COMMAND sh -c "gen.py --out a.c"
+    VERBATIM
+)
+`, render(f))
+}
+
+func TestLayout(t *testing.T) {
+	f := FileFactory()
+	f.Header().NewCommand("cmake_minimum_required").AddKeyword("VERSION", "3.24")
+
+	b, _ := f.NewBlock("b")
+	b.NewCommand("add_executable", "b", "b.c")
+	b, _ = f.NewBlock("a")
+	b.NewCommand("add_executable", "a", "a.c")
+	b.NewCommand("set_target_properties", "a").AddKeyword("PROPERTIES", "PREFIX", "")
+	f.NewBlock("empty")
+
+	_, err := f.NewBlock("a")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, `cmake_minimum_required(VERSION 3.24)
+
+add_executable(a a.c)
+set_target_properties(a PROPERTIES PREFIX "")
+
+add_executable(b b.c)
+`, render(f))
+}
+
+func TestTree(t *testing.T) {
+	tr := TreeFactory()
+
+	a := tr.File("a")
+	tr.File("")
+	assert.Equal(t, a, tr.File("a"))
+	assert.Equal(t, []string{"", "a"}, tr.Dirs())
+}
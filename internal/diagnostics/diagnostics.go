@@ -0,0 +1,100 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Diagnostic is an error found in the definition of a module.
+type Diagnostic struct {
+	// The .bp file defining the module
+	File string
+	// Position of the offending property, or zero when unknown
+	Line    int
+	Column  int
+	Module  string
+	Message string
+}
+
+// String formats the diagnostic as `file:line:col: module "name": message`,
+// which editors and terminals recognize as a location. Diagnostics about
+// the configuration, rather than a module, omit the module.
+func (d Diagnostic) String() string {
+	pos := d.File
+	if d.Line > 0 {
+		pos += ":" + strconv.Itoa(d.Line) + ":" + strconv.Itoa(d.Column)
+	}
+	if d.Module == "" {
+		return fmt.Sprintf("%s: %s", pos, d.Message)
+	}
+	return fmt.Sprintf("%s: module %q: %s", pos, d.Module, d.Message)
+}
+
+func (d Diagnostic) less(o Diagnostic) bool {
+	if d.File != o.File {
+		return d.File < o.File
+	}
+	if d.Line != o.Line {
+		return d.Line < o.Line
+	}
+	if d.Column != o.Column {
+		return d.Column < o.Column
+	}
+	if d.Module != o.Module {
+		return d.Module < o.Module
+	}
+	return d.Message < o.Message
+}
+
+// Collector gathers the diagnostics of a run, so that they can all be
+// reported together rather than stopping at the first. It is safe to use
+// from parallel mutators.
+type Collector struct {
+	mu          sync.Mutex
+	diagnostics map[Diagnostic]bool
+}
+
+func New() *Collector {
+	return &Collector{diagnostics: map[Diagnostic]bool{}}
+}
+
+// Add records a diagnostic. Module variants raising the same diagnostic
+// only record it once.
+func (c *Collector) Add(d Diagnostic) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.diagnostics[d] = true
+}
+
+// Len returns the number of diagnostics recorded.
+func (c *Collector) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.diagnostics)
+}
+
+// Sorted returns the diagnostics ordered by file and position.
+func (c *Collector) Sorted() []Diagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sorted := make([]Diagnostic, 0, len(c.diagnostics))
+	for d := range c.diagnostics {
+		sorted = append(sorted, d)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].less(sorted[j]) })
+	return sorted
+}
+
+// Report writes the diagnostics to `out`, one per line, and returns how
+// many there were.
+func (c *Collector) Report(out io.Writer) int {
+	sorted := c.Sorted()
+	for _, d := range sorted {
+		fmt.Fprintln(out, d.String())
+	}
+	return len(sorted)
+}
@@ -0,0 +1,47 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	d := Diagnostic{File: "A/build.bp", Line: 12, Column: 5, Module: "libfoo", Message: "has field reexport_libs set"}
+	assert.Equal(t, "A/build.bp:12:5: module \"libfoo\": has field reexport_libs set", d.String())
+
+	d = Diagnostic{File: "A/build.bp", Module: "libfoo", Message: "has field reexport_libs set"}
+	assert.Equal(t, "A/build.bp: module \"libfoo\": has field reexport_libs set", d.String())
+
+	d = Diagnostic{File: ".config", Message: "target variant 'srcs' has the same name as a property of bob_binary"}
+	assert.Equal(t, ".config: target variant 'srcs' has the same name as a property of bob_binary", d.String())
+}
+
+func TestReportSorted(t *testing.T) {
+	const expected string = "A/build.bp:3:1: module \"first\": one\n" +
+		"A/build.bp:12:5: module \"second\": two\n" +
+		"A/build.bp:12:9: module \"second\": three\n" +
+		"B/build.bp:1:1: module \"third\": four\n"
+
+	c := New()
+	c.Add(Diagnostic{File: "B/build.bp", Line: 1, Column: 1, Module: "third", Message: "four"})
+	c.Add(Diagnostic{File: "A/build.bp", Line: 12, Column: 9, Module: "second", Message: "three"})
+	c.Add(Diagnostic{File: "A/build.bp", Line: 3, Column: 1, Module: "first", Message: "one"})
+	c.Add(Diagnostic{File: "A/build.bp", Line: 12, Column: 5, Module: "second", Message: "two"})
+
+	var out strings.Builder
+	assert.Equal(t, 4, c.Report(&out))
+	assert.Equal(t, expected, out.String())
+}
+
+func TestAddDuplicate(t *testing.T) {
+	c := New()
+	d := Diagnostic{File: "A/build.bp", Line: 3, Column: 1, Module: "first", Message: "one"}
+
+	// Each variant of a module raises the same diagnostic
+	c.Add(d)
+	c.Add(d)
+
+	assert.Equal(t, 1, c.Len())
+}
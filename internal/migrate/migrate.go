@@ -0,0 +1,446 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"text/scanner"
+
+	"github.com/google/blueprint/parser"
+
+	"github.com/ARM-software/bob-build/internal/diagnostics"
+)
+
+// The properties of `bob_binary` which `bob_executable` supports, and what
+// they are called there. Properties not listed cannot be converted.
+var executableProperties = map[string]string{
+	"name":                  "name",
+	"srcs":                  "srcs",
+	"enabled":               "enabled",
+	"build_by_default":      "build_by_default",
+	"tags":                  "tags",
+	"host_supported":        "host_supported",
+	"target_supported":      "target_supported",
+	"install_group":         "install_group",
+	"install_deps":          "install_deps",
+	"relative_install_path": "relative_install_path",
+	"post_install_tool":     "post_install_tool",
+	"post_install_cmd":      "post_install_cmd",
+	"post_install_args":     "post_install_args",
+	"install_symlinks":      "install_symlinks",
+	"tidy":                  "tidy",
+	"tidy_checks":           "tidy_checks",
+	"tidy_checks_as_errors": "tidy_checks_as_errors",
+	"tidy_flags":            "tidy_flags",
+	"coverage":              "coverage",
+	"cflags":                "copts",
+	"ldflags":               "linkopts",
+	"ldlibs":                "linkopts",
+	"static_libs":           "deps",
+	"shared_libs":           "deps",
+}
+
+// The properties a `bob_defaults` may have to be replaced by a
+// `bob_filegroup` of the same name, rather than moving its sources out.
+var filegroupProperties = map[string]bool{
+	"name": true,
+	"srcs": true,
+}
+
+type module struct {
+	file *parser.File
+	m    *parser.Module
+}
+
+type migrator struct {
+	files   []*parser.File
+	modules map[string]*module
+	issues  *diagnostics.Collector
+
+	// The filegroup holding the sources of each converted defaults
+	filegroups map[string]string
+	// The defaults which have been replaced by a filegroup
+	replaced map[string]bool
+}
+
+// Migrate rewrites the legacy modules of the files to the strict module
+// types, in place. Modules which cannot be fully converted are left as
+// they are, and the reasons are returned, sorted by position.
+//
+// - `bob_binary` becomes `bob_executable`, with `cflags` mapped to
+// `copts`, `ldflags` and `ldlibs` to `linkopts`, and the libraries to `deps`.
+// - The `srcs` of `bob_defaults` move to a `bob_filegroup`, which is added
+// to the `srcs` of the modules using the defaults.
+func Migrate(files []*parser.File) []diagnostics.Diagnostic {
+	mg := &migrator{
+		files:      files,
+		modules:    map[string]*module{},
+		issues:     diagnostics.New(),
+		filegroups: map[string]string{},
+		replaced:   map[string]bool{},
+	}
+
+	for _, f := range files {
+		for _, def := range f.Defs {
+			if m, ok := def.(*parser.Module); ok {
+				mg.modules[moduleName(m)] = &module{f, m}
+			}
+		}
+	}
+
+	for _, f := range files {
+		// Moving sources may add modules to the file
+		for _, def := range append([]parser.Definition{}, f.Defs...) {
+			if m, ok := def.(*parser.Module); ok && m.Type == "bob_defaults" {
+				mg.convertDefaults(f, m)
+			}
+		}
+	}
+
+	// Find the users of the defaults before they are removed from the
+	// `defaults` lists.
+	for _, f := range files {
+		for _, def := range f.Defs {
+			if m, ok := def.(*parser.Module); ok && m.Type != "bob_defaults" {
+				mg.addFilegroups(f, m)
+			}
+		}
+	}
+
+	for _, f := range files {
+		for _, def := range f.Defs {
+			if m, ok := def.(*parser.Module); ok {
+				mg.removeReplacedDefaults(f, m)
+			}
+		}
+	}
+
+	for _, f := range files {
+		for _, def := range f.Defs {
+			if m, ok := def.(*parser.Module); ok && m.Type == "bob_binary" {
+				mg.convertBinary(f, m)
+			}
+		}
+	}
+
+	return mg.issues.Sorted()
+}
+
+func (mg *migrator) report(f *parser.File, m *parser.Module, pos scanner.Position, format string, args ...interface{}) {
+	mg.issues.Add(diagnostics.Diagnostic{
+		File:    f.Name,
+		Line:    pos.Line,
+		Column:  pos.Column,
+		Module:  moduleName(m),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func moduleName(m *parser.Module) string {
+	if p := property(&m.Map, "name"); p != nil {
+		if s, ok := p.Value.(*parser.String); ok {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+func property(mp *parser.Map, name string) *parser.Property {
+	for _, p := range mp.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func removeProperty(mp *parser.Map, name string) {
+	for i, p := range mp.Properties {
+		if p.Name == name {
+			mp.Properties = append(mp.Properties[:i], mp.Properties[i+1:]...)
+			return
+		}
+	}
+}
+
+// stringList returns the values of a literal list of strings.
+func stringList(e parser.Expression) ([]string, bool) {
+	l, ok := e.(*parser.List)
+	if !ok {
+		return nil, false
+	}
+	values := []string{}
+	for _, v := range l.Values {
+		s, ok := v.(*parser.String)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, s.Value)
+	}
+	return values, true
+}
+
+// nestedProperty finds a property in the feature, host and target blocks
+// of a module, which are the properties holding maps.
+func nestedProperty(mp *parser.Map, name string) *parser.Property {
+	for _, p := range mp.Properties {
+		if nested, ok := p.Value.(*parser.Map); ok {
+			if found := property(nested, name); found != nil {
+				return found
+			}
+			if found := nestedProperty(nested, name); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+func (mg *migrator) convertDefaults(f *parser.File, m *parser.Module) {
+	srcs := property(&m.Map, "srcs")
+	if srcs == nil {
+		return
+	}
+	name := moduleName(m)
+
+	ok := true
+	if p := property(&m.Map, "exclude_srcs"); p != nil {
+		mg.report(f, m, p.NamePos, "`exclude_srcs` cannot be moved to a bob_filegroup")
+		ok = false
+	}
+	if p := nestedProperty(&m.Map, "srcs"); p != nil {
+		mg.report(f, m, p.NamePos, "feature or target specific `srcs` cannot be moved to a bob_filegroup")
+		ok = false
+	}
+	if values, isList := stringList(srcs.Value); isList {
+		for _, src := range values {
+			if strings.ContainsAny(src, "*?[") {
+				mg.report(f, m, srcs.NamePos, "`%s` is a glob, which bob_filegroup does not support; use a bob_glob", src)
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		return
+	}
+
+	inPlace := true
+	for _, p := range m.Properties {
+		if !filegroupProperties[p.Name] {
+			inPlace = false
+		}
+	}
+
+	if inPlace {
+		m.Type = "bob_filegroup"
+		mg.filegroups[name] = name
+		mg.replaced[name] = true
+		return
+	}
+
+	fgName := name + "_srcs"
+	if _, exists := mg.modules[fgName]; exists {
+		mg.report(f, m, srcs.NamePos, "cannot move `srcs` to a bob_filegroup, as `%s` already exists", fgName)
+		return
+	}
+
+	pos := m.RBracePos
+	fg := &parser.Module{
+		Type:    "bob_filegroup",
+		TypePos: pos,
+		Map: parser.Map{
+			LBracePos: pos,
+			RBracePos: pos,
+			Properties: []*parser.Property{
+				{
+					Name:     "name",
+					NamePos:  pos,
+					ColonPos: pos,
+					Value:    &parser.String{LiteralPos: pos, Value: fgName},
+				},
+				srcs,
+			},
+		},
+	}
+	removeProperty(&m.Map, "srcs")
+
+	for i, def := range f.Defs {
+		if def == m {
+			f.Defs = append(f.Defs[:i+1], append([]parser.Definition{fg}, f.Defs[i+1:]...)...)
+			break
+		}
+	}
+	mg.modules[fgName] = &module{f, fg}
+	mg.filegroups[name] = fgName
+}
+
+// expandDefaults lists the defaults a module uses, including through other
+// defaults, children first.
+func (mg *migrator) expandDefaults(m *parser.Module, visited map[string]bool) []string {
+	p := property(&m.Map, "defaults")
+	if p == nil {
+		return nil
+	}
+	names, _ := stringList(p.Value)
+
+	expanded := []string{}
+	for _, name := range names {
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		if d, ok := mg.modules[name]; ok {
+			expanded = append(expanded, mg.expandDefaults(d.m, visited)...)
+		}
+		expanded = append(expanded, name)
+	}
+	return expanded
+}
+
+func (mg *migrator) addFilegroups(f *parser.File, m *parser.Module) {
+	if p := property(&m.Map, "defaults"); p != nil {
+		if _, ok := stringList(p.Value); !ok && len(mg.filegroups) > 0 {
+			mg.report(f, m, p.NamePos, "`defaults` is not a list of strings, so sources moved to filegroups cannot be added")
+			return
+		}
+	}
+
+	added := []string{}
+	for _, d := range mg.expandDefaults(m, map[string]bool{}) {
+		if fg, ok := mg.filegroups[d]; ok {
+			added = append(added, ":"+fg)
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	srcs := property(&m.Map, "srcs")
+	if srcs == nil {
+		pos := m.RBracePos
+		srcs = &parser.Property{
+			Name:     "srcs",
+			NamePos:  pos,
+			ColonPos: pos,
+			Value:    &parser.List{LBracePos: pos, RBracePos: pos},
+		}
+		m.Properties = append(m.Properties, srcs)
+	}
+
+	l, ok := srcs.Value.(*parser.List)
+	if !ok {
+		mg.report(f, m, srcs.NamePos, "`srcs` is not a list, so `%s` cannot be added to it", strings.Join(added, "`, `"))
+		return
+	}
+	for _, fg := range added {
+		l.Values = append(l.Values, &parser.String{LiteralPos: l.RBracePos, Value: fg})
+	}
+}
+
+func (mg *migrator) removeReplacedDefaults(f *parser.File, m *parser.Module) {
+	p := property(&m.Map, "defaults")
+	if p == nil {
+		return
+	}
+	l, ok := p.Value.(*parser.List)
+	if !ok {
+		return
+	}
+
+	kept := []parser.Expression{}
+	for _, v := range l.Values {
+		if s, ok := v.(*parser.String); ok && mg.replaced[s.Value] {
+			continue
+		}
+		kept = append(kept, v)
+	}
+	l.Values = kept
+
+	if len(kept) == 0 {
+		removeProperty(&m.Map, "defaults")
+	}
+}
+
+// checkExecutable reports the properties of a `bob_binary` which
+// `bob_executable` cannot express, returning whether there were none.
+func (mg *migrator) checkExecutable(f *parser.File, m *parser.Module, mp *parser.Map) bool {
+	ok := true
+	renamed := map[string]*parser.Property{}
+
+	for _, p := range mp.Properties {
+		if nested, isMap := p.Value.(*parser.Map); isMap {
+			// Feature, host and target blocks
+			ok = mg.checkExecutable(f, m, nested) && ok
+			continue
+		}
+
+		switch p.Name {
+		case "defaults":
+			mg.report(f, m, p.NamePos, "bob_executable does not support `defaults`; move their properties into the module")
+			ok = false
+			continue
+		case "srcs":
+			if values, isList := stringList(p.Value); isList {
+				for _, src := range values {
+					if strings.ContainsAny(src, "*?[") {
+						mg.report(f, m, p.NamePos, "`%s` is a glob, which bob_executable does not support; use a bob_glob", src)
+						ok = false
+					}
+				}
+			}
+		}
+
+		to, known := executableProperties[p.Name]
+		if !known {
+			mg.report(f, m, p.NamePos, "`%s` has no equivalent in bob_executable", p.Name)
+			ok = false
+			continue
+		}
+
+		// Properties merged into one must be lists to be concatenated
+		if other, exists := renamed[to]; exists {
+			if _, isList := other.Value.(*parser.List); !isList {
+				mg.report(f, m, other.NamePos, "`%s` is not a list, so it cannot be merged into `%s`", other.Name, to)
+				ok = false
+			}
+			if _, isList := p.Value.(*parser.List); !isList {
+				mg.report(f, m, p.NamePos, "`%s` is not a list, so it cannot be merged into `%s`", p.Name, to)
+				ok = false
+			}
+		}
+		renamed[to] = p
+	}
+
+	return ok
+}
+
+func convertExecutableProperties(mp *parser.Map) {
+	kept := []*parser.Property{}
+	renamed := map[string]*parser.Property{}
+
+	for _, p := range mp.Properties {
+		if nested, isMap := p.Value.(*parser.Map); isMap {
+			convertExecutableProperties(nested)
+			kept = append(kept, p)
+			continue
+		}
+
+		p.Name = executableProperties[p.Name]
+		if other, exists := renamed[p.Name]; exists {
+			l := other.Value.(*parser.List)
+			l.Values = append(l.Values, p.Value.(*parser.List).Values...)
+			continue
+		}
+		renamed[p.Name] = p
+		kept = append(kept, p)
+	}
+
+	mp.Properties = kept
+}
+
+func (mg *migrator) convertBinary(f *parser.File, m *parser.Module) {
+	if !mg.checkExecutable(f, m, &m.Map) {
+		return
+	}
+	m.Type = "bob_executable"
+	convertExecutableProperties(&m.Map)
+}
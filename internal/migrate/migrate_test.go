@@ -0,0 +1,210 @@
+package migrate
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/blueprint/parser"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ARM-software/bob-build/internal/diagnostics"
+)
+
+func str(s string) *parser.String {
+	return &parser.String{Value: s}
+}
+
+func list(values ...string) *parser.List {
+	l := &parser.List{}
+	for _, v := range values {
+		l.Values = append(l.Values, str(v))
+	}
+	return l
+}
+
+func prop(name string, value parser.Expression) *parser.Property {
+	return &parser.Property{Name: name, Value: value}
+}
+
+func block(props ...*parser.Property) *parser.Map {
+	return &parser.Map{Properties: props}
+}
+
+func mod(typ string, name string, props ...*parser.Property) *parser.Module {
+	return &parser.Module{
+		Type: typ,
+		Map:  parser.Map{Properties: append([]*parser.Property{prop("name", str(name))}, props...)},
+	}
+}
+
+func file(name string, modules ...*parser.Module) *parser.File {
+	f := &parser.File{Name: name}
+	for _, m := range modules {
+		f.Defs = append(f.Defs, m)
+	}
+	return f
+}
+
+// describe writes a module as `type name { prop: value ... }`, to compare
+// the rewritten files.
+func describe(def parser.Definition) string {
+	m := def.(*parser.Module)
+	return m.Type + " " + moduleName(m) + " " + describeMap(&m.Map)
+}
+
+func describeMap(mp *parser.Map) string {
+	props := []string{}
+	for _, p := range mp.Properties {
+		if p.Name == "name" {
+			continue
+		}
+		var value string
+		switch v := p.Value.(type) {
+		case *parser.String:
+			value = v.Value
+		case *parser.List:
+			values, _ := stringList(v)
+			value = "[" + strings.Join(values, " ") + "]"
+		case *parser.Map:
+			value = describeMap(v)
+		case *parser.Variable:
+			value = "$" + v.Name
+		}
+		props = append(props, p.Name+": "+value)
+	}
+	return "{" + strings.Join(props, ", ") + "}"
+}
+
+func describeAll(f *parser.File) []string {
+	descs := []string{}
+	for _, def := range f.Defs {
+		descs = append(descs, describe(def))
+	}
+	return descs
+}
+
+func messages(issues []diagnostics.Diagnostic) []string {
+	msgs := []string{}
+	for _, d := range issues {
+		msgs = append(msgs, d.Module+": "+d.Message)
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+func TestBinaryToExecutable(t *testing.T) {
+	f := file("build.bp",
+		mod("bob_binary", "bin",
+			prop("srcs", list("main.c")),
+			prop("cflags", list("-Wall")),
+			prop("static_libs", list("libstatic")),
+			prop("shared_libs", list("libshared")),
+			prop("ldlibs", list("-lm")),
+			prop("host", block(prop("cflags", list("-DHOST")))),
+			prop("my_feature", block(prop("ldflags", list("-Wl,--as-needed")))),
+		))
+
+	issues := Migrate([]*parser.File{f})
+
+	assert.Equal(t, []string{}, messages(issues))
+	assert.Equal(t, []string{
+		"bob_executable bin {srcs: [main.c], copts: [-Wall], deps: [libstatic libshared], linkopts: [-lm], " +
+			"host: {copts: [-DHOST]}, my_feature: {linkopts: [-Wl,--as-needed]}}",
+	}, describeAll(f))
+}
+
+func TestBinaryNotConverted(t *testing.T) {
+	f := file("build.bp",
+		mod("bob_binary", "bin",
+			prop("srcs", list("src/*.c")),
+			prop("cxxflags", list("-std=c++17")),
+			prop("static_libs", &parser.Variable{Name: "libs"}),
+			prop("shared_libs", list("libshared")),
+			prop("target", block(prop("export_cflags", list("-DFOO")))),
+		))
+
+	issues := Migrate([]*parser.File{f})
+
+	assert.Equal(t, []string{
+		"bin: `cxxflags` has no equivalent in bob_executable",
+		"bin: `export_cflags` has no equivalent in bob_executable",
+		"bin: `src/*.c` is a glob, which bob_executable does not support; use a bob_glob",
+		"bin: `static_libs` is not a list, so it cannot be merged into `deps`",
+	}, messages(issues))
+	// Left unchanged
+	assert.Equal(t, []string{
+		"bob_binary bin {srcs: [src/*.c], cxxflags: [-std=c++17], static_libs: $libs, shared_libs: [libshared], " +
+			"target: {export_cflags: [-DFOO]}}",
+	}, describeAll(f))
+}
+
+func TestDefaultsToFilegroup(t *testing.T) {
+	a := file("a/build.bp",
+		mod("bob_defaults", "common_srcs",
+			prop("srcs", list("common.c")),
+		),
+		mod("bob_defaults", "common_flags",
+			prop("srcs", list("flags.c")),
+			prop("cflags", list("-Wall")),
+		),
+		mod("bob_defaults", "derived",
+			prop("defaults", list("common_flags")),
+			prop("ldlibs", list("-lm")),
+		))
+	b := file("b/build.bp",
+		mod("bob_binary", "bin",
+			prop("defaults", list("common_srcs")),
+			prop("srcs", list("main.c")),
+		),
+		mod("bob_static_library", "libfoo",
+			prop("defaults", list("common_srcs", "derived")),
+		))
+
+	issues := Migrate([]*parser.File{a, b})
+
+	assert.Equal(t, []string{}, messages(issues))
+	assert.Equal(t, []string{
+		"bob_filegroup common_srcs {srcs: [common.c]}",
+		"bob_defaults common_flags {cflags: [-Wall]}",
+		"bob_filegroup common_flags_srcs {srcs: [flags.c]}",
+		"bob_defaults derived {defaults: [common_flags], ldlibs: [-lm]}",
+	}, describeAll(a))
+	// Once its only defaults are gone, the binary can be converted too
+	assert.Equal(t, []string{
+		"bob_executable bin {srcs: [main.c :common_srcs]}",
+		"bob_static_library libfoo {defaults: [derived], srcs: [:common_srcs :common_flags_srcs]}",
+	}, describeAll(b))
+}
+
+func TestDefaultsNotConverted(t *testing.T) {
+	f := file("build.bp",
+		mod("bob_defaults", "excluded",
+			prop("srcs", list("*.c")),
+			prop("exclude_srcs", list("test.c")),
+		),
+		mod("bob_defaults", "featured",
+			prop("srcs", list("a.c")),
+			prop("my_feature", block(prop("srcs", list("b.c")))),
+		),
+		mod("bob_defaults", "clash",
+			prop("srcs", list("a.c")),
+			prop("cflags", list("-Wall")),
+		),
+		mod("bob_filegroup", "clash_srcs"),
+		mod("bob_binary", "bin",
+			prop("defaults", list("excluded", "featured", "clash")),
+			prop("srcs", list("main.c")),
+		))
+
+	issues := Migrate([]*parser.File{f})
+
+	assert.Equal(t, []string{
+		"bin: bob_executable does not support `defaults`; move their properties into the module",
+		"clash: cannot move `srcs` to a bob_filegroup, as `clash_srcs` already exists",
+		"excluded: `*.c` is a glob, which bob_filegroup does not support; use a bob_glob",
+		"excluded: `exclude_srcs` cannot be moved to a bob_filegroup",
+		"featured: feature or target specific `srcs` cannot be moved to a bob_filegroup",
+	}, messages(issues))
+	assert.Equal(t, "bob_binary bin {defaults: [excluded featured clash], srcs: [main.c]}", describe(f.Defs[4]))
+}
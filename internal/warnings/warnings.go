@@ -1,10 +1,11 @@
 package warnings
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,14 +21,26 @@ const (
 	RelativeUpLinkWarning             Category = "relative-up-link"
 	UnmatchedNonCompileSrcsWarning    Category = "unmatched-non-compile-srcs"
 	AndroidOutOfTreeUnsupportedModule Category = "android-out-of-tree-unsupported-module"
+	BazelUnsupportedModule            Category = "bazel-unsupported-module"
+	CMakeUnsupportedModule            Category = "cmake-unsupported-module"
+	UnusedDefaultsWarning             Category = "unused-defaults"
+	UnreferencedDepWarning            Category = "unreferenced-dep"
+	DuplicateSrcsWarning              Category = "duplicate-srcs"
 )
 
+// The known categories, by the name used in filters. Further categories
+// can be added with RegisterCategory, so all accesses hold categoriesLock.
 var categoriesMap = map[string]Category{
 	"DefaultSrcsWarning":                DefaultSrcsWarning,
 	"GenerateRuleWarning":               GenerateRuleWarning,
 	"RelativeUpLinkWarning":             RelativeUpLinkWarning,
 	"UnmatchedNonCompileSrcsWarning":    UnmatchedNonCompileSrcsWarning,
 	"AndroidOutOfTreeUnsupportedModule": AndroidOutOfTreeUnsupportedModule,
+	"BazelUnsupportedModule":            BazelUnsupportedModule,
+	"CMakeUnsupportedModule":            CMakeUnsupportedModule,
+	"UnusedDefaultsWarning":             UnusedDefaultsWarning,
+	"UnreferencedDepWarning":            UnreferencedDepWarning,
+	"DuplicateSrcsWarning":              DuplicateSrcsWarning,
 }
 
 var categoriesMessages = map[Category]string{
@@ -36,6 +49,99 @@ var categoriesMessages = map[Category]string{
 	RelativeUpLinkWarning:             "Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead.",
 	UnmatchedNonCompileSrcsWarning:    "Non-compiled sources have not been matched fully.",
 	AndroidOutOfTreeUnsupportedModule: "Android of out tree does not support all module types yet.",
+	BazelUnsupportedModule:            "`%s` modules cannot be exported to Bazel.",
+	CMakeUnsupportedModule:            "`%s` modules cannot be exported to CMake.",
+	UnusedDefaultsWarning:             "`bob_defaults` module is not used by any module.",
+	UnreferencedDepWarning:            "`%s` is in `generated_deps`, but `${%[1]s_out}` is not used.",
+	DuplicateSrcsWarning:              "`%s` is listed more than once in `srcs`.",
+}
+
+// The property each category refers to, used to locate warnings. The
+// other categories refer to the module itself.
+var categoriesProperties = map[Category]string{
+	DefaultSrcsWarning:             "srcs",
+	RelativeUpLinkWarning:          "srcs",
+	UnmatchedNonCompileSrcsWarning: "srcs",
+	UnreferencedDepWarning:         "generated_deps",
+	DuplicateSrcsWarning:           "srcs",
+}
+
+// Documentation of registered categories which is not under URL.
+var categoriesURLs = map[Category]string{}
+
+var categoriesLock sync.RWMutex
+
+// CategoryInfo describes a warning category added by a project, such as
+// one of its own lint rules.
+type CategoryInfo struct {
+	// The name used in filter expressions, e.g. `VendorIncludeWarning`
+	Name string
+	// The identifier written in logs, e.g. `vendor-include`
+	Category Category
+	// The message format, which is passed the arguments of Warn
+	Message string
+	// The property the warning refers to, if any, used to locate it
+	Property string
+	// Documentation of the category. Defaults to a page under URL.
+	URL string
+}
+
+// RegisterCategory makes a new category available to Warn and to filter
+// expressions. Categories must be registered before the logger parsing the
+// filters is created.
+func RegisterCategory(info CategoryInfo) error {
+	if info.Name == "" || info.Name == "*" || info.Category == "" {
+		return errors.New("Warning category needs a name and a category")
+	}
+
+	categoriesLock.Lock()
+	defer categoriesLock.Unlock()
+
+	if _, ok := categoriesMap[info.Name]; ok {
+		return fmt.Errorf("Warning category '%s' already registered", info.Name)
+	}
+	if _, ok := categoriesMessages[info.Category]; ok {
+		return fmt.Errorf("Warning category '%s' already registered", info.Category)
+	}
+
+	categoriesMap[info.Name] = info.Category
+	categoriesMessages[info.Category] = info.Message
+	if info.Property != "" {
+		categoriesProperties[info.Category] = info.Property
+	}
+	if info.URL != "" {
+		categoriesURLs[info.Category] = info.URL
+	}
+
+	return nil
+}
+
+func lookupCategory(name string) (Category, bool) {
+	categoriesLock.RLock()
+	defer categoriesLock.RUnlock()
+	c, ok := categoriesMap[name]
+	return c, ok
+}
+
+func categoryMessage(c Category) string {
+	categoriesLock.RLock()
+	defer categoriesLock.RUnlock()
+	return categoriesMessages[c]
+}
+
+func categoryProperty(c Category) string {
+	categoriesLock.RLock()
+	defer categoriesLock.RUnlock()
+	return categoriesProperties[c]
+}
+
+func categoryURL(c Category) string {
+	categoriesLock.RLock()
+	defer categoriesLock.RUnlock()
+	if url, ok := categoriesURLs[c]; ok {
+		return url
+	}
+	return URL + string(c) + ".md"
 }
 
 type Action string
@@ -52,28 +158,98 @@ var actionsMap = map[string]Action{
 	"E": ErrorAction,
 }
 
-type WarningLogger struct {
-	out          *csv.Writer
-	mu           sync.Mutex
+// Locator returns the line and column of a property of a module in a
+// .bp file, or zeros when unknown. An empty property refers to the module.
+type Locator func(bpFile string, bpModule string, property string) (line int, column int)
+
+// A scope limits filters to the modules defined under a directory, or to
+// the modules with a tag. The zero scope is global.
+type scope struct {
+	dir string
+	tag string
+}
+
+var globalScope = scope{}
+
+// The actions set by the filters of one scope. An empty globalAction
+// means the wildcard was not set in the scope.
+type policy struct {
 	filters      map[Category]Action
 	globalAction Action
-	errors       int
-	hypelinks    bool
+}
+
+type WarningLogger struct {
+	out           writer
+	mu            sync.Mutex
+	policies      map[scope]*policy
+	defaultAction Action
+	errors        int
+	hypelinks     bool
+	locate        Locator
 }
 
 func New(out io.Writer, filters string) *WarningLogger {
-	w := csv.NewWriter(out)
-	w.Write([]string{"BpFile", "BpModule", "WarningAction", "WarningMessage", "WarningCategory"})
-	w.Flush()
+	return NewWithFormat(out, CSVFormat, filters)
+}
+
+func NewWithFormat(out io.Writer, format Format, filters string) *WarningLogger {
+	return &WarningLogger{
+		out:           newWriter(out, format),
+		policies:      parseFilters(filters),
+		defaultAction: IgnoreAction,
+		hypelinks:     checkIfHyperlinks(),
+	}
+}
+
+// SetDefaultAction sets the action of the categories which no filter,
+// including a wildcard, applies to. It is IgnoreAction unless set.
+func (w *WarningLogger) SetDefaultAction(action Action) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.defaultAction = action
+}
 
-	f, g := parseFilters(filters)
+// SetLocator sets the function finding where warnings are in .bp files.
+func (w *WarningLogger) SetLocator(locate Locator) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.locate = locate
+}
+
+// SetSourceRoot sets the directory which the .bp files are relative to.
+// SARIF logs give it as the `%SRCROOT%` base of their locations.
+func (w *WarningLogger) SetSourceRoot(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if sw, ok := w.out.(*sarifWriter); ok {
+		sw.srcRoot = dir
+	}
+}
+
+// Close writes out anything the format holds until the end, such as the
+// SARIF log.
+func (w *WarningLogger) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.close()
+}
 
-	return &WarningLogger{out: w, filters: f, globalAction: g, hypelinks: checkIfHyperlinks()}
+func parseScope(s string) (scope, bool) {
+	if strings.HasPrefix(s, "tag=") {
+		tag := strings.TrimPrefix(s, "tag=")
+		return scope{tag: tag}, tag != ""
+	}
+	if s == "" {
+		return globalScope, false
+	}
+	return scope{dir: filepath.Clean(s)}, true
 }
 
-func parseFilters(f string) (filters map[Category]Action, globalAction Action) {
+func parseFilters(f string) (policies map[scope]*policy) {
 
-	filters = make(map[Category]Action)
+	policies = map[scope]*policy{
+		globalScope: {filters: make(map[Category]Action)},
+	}
 
 	fn := func(c rune) bool {
 		return c == ' '
@@ -81,7 +257,8 @@ func parseFilters(f string) (filters map[Category]Action, globalAction Action) {
 
 	if f != "" {
 		for _, subFilter := range strings.FieldsFunc(f, fn) {
-			parts := strings.SplitN(subFilter, ":", 2)
+			exprs := strings.SplitN(subFilter, "@", 2)
+			parts := strings.SplitN(exprs[0], ":", 2)
 
 			if len(parts) != 2 {
 				fmt.Fprintf(os.Stderr, "Wrong warnings filter expression '%s'\n", subFilter)
@@ -95,39 +272,90 @@ func parseFilters(f string) (filters map[Category]Action, globalAction Action) {
 				continue
 			}
 
+			sc := globalScope
+			if len(exprs) == 2 {
+				var ok bool
+				if sc, ok = parseScope(exprs[1]); !ok {
+					fmt.Fprintf(os.Stderr, "Wrong filter scope '%s'\n", subFilter)
+					continue
+				}
+			}
+
+			p, ok := policies[sc]
+			if !ok {
+				p = &policy{filters: make(map[Category]Action)}
+				policies[sc] = p
+			}
+
 			if c == "*" {
-				if globalAction != "" {
+				if p.globalAction != "" {
 					fmt.Fprintf(os.Stderr, "Overriding wildcard (*) not allowed: '%s'\n", subFilter)
 				} else {
-					globalAction = actionsMap[a]
+					p.globalAction = actionsMap[a]
 				}
 
 				continue
 			}
 
-			if category, ok := categoriesMap[c]; ok {
-				if _, ok := filters[category]; ok {
+			if category, ok := lookupCategory(c); ok {
+				if _, ok := p.filters[category]; ok {
 					fmt.Fprintf(os.Stderr, "Overriding warning category not allowed: '%s'\n", subFilter)
 					continue
 				}
 
-				filters[category] = actionsMap[a]
+				p.filters[category] = actionsMap[a]
 			} else {
 				fmt.Fprintf(os.Stderr, "Wrong filter category '%s'\n", subFilter)
 			}
 		}
 	}
 
-	if globalAction == "" {
-		globalAction = IgnoreAction
+	return
+}
+
+// scopesOf lists the scopes applying to a module, most specific first:
+// its tags in order, then the directories of its .bp file from the
+// deepest to the root, then the global scope.
+func scopesOf(bpFile string, tags []string) []scope {
+	scopes := []scope{}
+	for _, tag := range tags {
+		scopes = append(scopes, scope{tag: tag})
 	}
 
-	return
+	dir := filepath.Dir(filepath.Clean(bpFile))
+	for {
+		scopes = append(scopes, scope{dir: dir})
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return append(scopes, globalScope)
+}
+
+// action returns the action of the most specific scope with a filter for
+// the category or a wildcard.
+func (w *WarningLogger) action(category Category, bpFile string, tags []string) Action {
+	for _, sc := range scopesOf(bpFile, tags) {
+		p, ok := w.policies[sc]
+		if !ok {
+			continue
+		}
+		if action, ok := p.filters[category]; ok {
+			return action
+		}
+		if p.globalAction != "" {
+			return p.globalAction
+		}
+	}
+	return w.defaultAction
 }
 
 func (w *WarningLogger) getLink(category Category) string {
 	if w.hypelinks {
-		return fmt.Sprintf("\x1b]8;;%[1]s%[2]s.md\x07%[2]s\x1b]8;;\x07", URL, category)
+		return fmt.Sprintf("\x1b]8;;%[1]s\x07%[2]s\x1b]8;;\x07", categoryURL(category), category)
 	} else {
 		return string(category)
 	}
@@ -147,18 +375,20 @@ func (w *WarningLogger) ErrorWarnings() int {
 }
 
 func (w *WarningLogger) getMessage(category Category, args ...interface{}) string {
-	return fmt.Sprintf(categoriesMessages[category], args...)
+	return fmt.Sprintf(categoryMessage(category), args...)
 }
 
 func (w *WarningLogger) Warn(category Category, bpFile string, bpModule string, args ...interface{}) error {
+	return w.WarnTagged(category, bpFile, bpModule, nil, args...)
+}
+
+// WarnTagged raises a warning about a module with tags, so that filters
+// scoped to any of the tags apply to it.
+func (w *WarningLogger) WarnTagged(category Category, bpFile string, bpModule string, tags []string, args ...interface{}) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	action, ok := w.filters[category]
-
-	if !ok {
-		action = w.globalAction
-	}
+	action := w.action(category, bpFile, tags)
 
 	if action == ErrorAction {
 		w.errors++
@@ -168,10 +398,18 @@ func (w *WarningLogger) Warn(category Category, bpFile string, bpModule string,
 		io.WriteString(os.Stderr, fmt.Sprintf("%s:%s: %s: %s [%s]\n", bpFile, bpModule, action, w.getMessage(category, args...), w.getLink(category)))
 	}
 
-	w.out.Write([]string{bpFile, bpModule, string(action), w.getMessage(category, args...), string(category)})
-	w.out.Flush()
+	e := &entry{
+		bpFile:   bpFile,
+		bpModule: bpModule,
+		action:   action,
+		message:  w.getMessage(category, args...),
+		category: category,
+	}
+	if w.locate != nil {
+		e.line, e.column = w.locate(bpFile, bpModule, categoryProperty(category))
+	}
 
-	return w.out.Error()
+	return w.out.write(e)
 }
 
 func checkIfHyperlinks() bool {
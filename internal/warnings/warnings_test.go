@@ -235,3 +235,123 @@ func TestInfoMessage(t *testing.T) {
 
 	assert.Equal(t, expected, str)
 }
+
+func TestScopedFilters(t *testing.T) {
+	const expectedStderr string = "vendor/foo/build.bp:lib_a: error: Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead. [relative-up-link]\n" +
+		"vendor/foo/bar/build.bp:lib_b: warning: Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead. [relative-up-link]\n" +
+		"vendor/foo/bar/build.bp:lib_c: warning: `bob_generate_source` should not be used. Use `bob_genrule` instead. [generate-rule]\n" +
+		"other/build.bp:lib_e: warning: Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead. [relative-up-link]\n"
+	var msg strings.Builder
+
+	wr := New(&msg, "RelativeUpLinkWarning:E@vendor/foo *:W@vendor/foo/bar RelativeUpLinkWarning:W@tag=owner:other")
+
+	stderrOutput := captureStderr(func() {
+		wr.Warn(RelativeUpLinkWarning, "vendor/foo/build.bp", "lib_a")
+		// The wildcard of a deeper directory wins over its parent's categories
+		wr.Warn(RelativeUpLinkWarning, "vendor/foo/bar/build.bp", "lib_b")
+		wr.Warn(GenerateRuleWarning, "vendor/foo/bar/build.bp", "lib_c")
+		wr.Warn(RelativeUpLinkWarning, "vendor/foobar/build.bp", "lib_d")
+		wr.WarnTagged(RelativeUpLinkWarning, "other/build.bp", "lib_e", []string{"owner:other"})
+	})
+
+	assert.Equal(t, expectedStderr, stderrOutput)
+	assert.Equal(t, 1, wr.ErrorWarnings())
+}
+
+func TestScopedFiltersTagsFirst(t *testing.T) {
+	const expectedStderr string = "vendor/foo/build.bp:lib_a: warning: Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead. [relative-up-link]\n"
+	var msg strings.Builder
+
+	wr := New(&msg, "*:E@vendor/foo RelativeUpLinkWarning:W@tag=legacy")
+
+	stderrOutput := captureStderr(func() {
+		wr.WarnTagged(RelativeUpLinkWarning, "vendor/foo/build.bp", "lib_a", []string{"legacy"})
+	})
+
+	assert.Equal(t, expectedStderr, stderrOutput)
+}
+
+func TestScopedFilterOverwrite(t *testing.T) {
+	const expected string = "Overriding warning category not allowed: 'GenerateRuleWarning:W@vendor/foo/'\n" +
+		"Wrong filter scope 'GenerateRuleWarning:W@'\n" +
+		"Wrong filter scope 'GenerateRuleWarning:W@tag='\n"
+	var msg strings.Builder
+
+	stderrOutput := captureStderr(func() {
+		// The same category may be set in different scopes, but only once in each
+		New(&msg, "GenerateRuleWarning:E GenerateRuleWarning:E@vendor/foo GenerateRuleWarning:W@vendor/foo/ "+
+			"GenerateRuleWarning:W@ GenerateRuleWarning:W@tag=")
+	})
+
+	assert.Equal(t, expected, stderrOutput)
+}
+
+func TestRegisterCategory(t *testing.T) {
+	const expectedStderr string = "A/build.bp:lib_a: error: `vendor/include` is included by `lib_a`. [vendor-include]\n"
+	const expected string = "BpFile,BpModule,WarningAction,WarningMessage,WarningCategory\n" +
+		"A/build.bp,lib_a,error,`vendor/include` is included by `lib_a`.,vendor-include\n"
+
+	err := RegisterCategory(CategoryInfo{
+		Name:     "VendorIncludeWarning",
+		Category: "vendor-include",
+		Message:  "`%s` is included by `%s`.",
+		Property: "include_dirs",
+	})
+	assert.Nil(t, err)
+
+	err = RegisterCategory(CategoryInfo{Name: "VendorIncludeWarning", Category: "vendor-include-2"})
+	assert.EqualError(t, err, "Warning category 'VendorIncludeWarning' already registered")
+	err = RegisterCategory(CategoryInfo{Name: "RelativeUpLink", Category: RelativeUpLinkWarning})
+	assert.EqualError(t, err, "Warning category 'relative-up-link' already registered")
+	err = RegisterCategory(CategoryInfo{Name: "*", Category: "all"})
+	assert.EqualError(t, err, "Warning category needs a name and a category")
+
+	var msg strings.Builder
+
+	wr := New(&msg, "VendorIncludeWarning:E")
+	stderrOutput := captureStderr(func() {
+		wr.Warn("vendor-include", "A/build.bp", "lib_a", "vendor/include", "lib_a")
+	})
+
+	assert.Equal(t, expected, msg.String())
+	assert.Equal(t, expectedStderr, stderrOutput)
+}
+
+func TestRegisterCategoryURL(t *testing.T) {
+	err := RegisterCategory(CategoryInfo{
+		Name:     "ProjectLicenseWarning",
+		Category: "project-license",
+		Message:  "Missing license.",
+		URL:      "https://example.com/lint/project-license.html",
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "https://example.com/lint/project-license.html", categoryURL("project-license"))
+	assert.Equal(t, URL+"generate-rule.md", categoryURL(GenerateRuleWarning))
+}
+
+func TestDefaultAction(t *testing.T) {
+	const expectedStderr string = "A/build.bp:gen_table: warning: `bob_generate_source` should not be used. Use `bob_genrule` instead. [generate-rule]\n"
+	var msg strings.Builder
+
+	wr := New(&msg, "RelativeUpLinkWarning:I")
+	wr.SetDefaultAction(WarningAction)
+
+	stderrOutput := captureStderr(func() {
+		wr.Warn(GenerateRuleWarning, "A/build.bp", "gen_table")
+		wr.Warn(RelativeUpLinkWarning, "B/build.bp", "gen_binary")
+	})
+
+	assert.Equal(t, expectedStderr, stderrOutput)
+
+	// A wildcard takes precedence over the default action
+	wr = New(&msg, "*:I")
+	wr.SetDefaultAction(ErrorAction)
+
+	stderrOutput = captureStderr(func() {
+		wr.Warn(GenerateRuleWarning, "A/build.bp", "gen_table")
+	})
+
+	assert.Equal(t, "", stderrOutput)
+	assert.Equal(t, 0, wr.ErrorWarnings())
+}
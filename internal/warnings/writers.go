@@ -0,0 +1,287 @@
+package warnings
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format selects how the warnings are written out.
+type Format string
+
+const (
+	CSVFormat   Format = "csv"
+	JSONLFormat Format = "jsonl"
+	SARIFFormat Format = "sarif"
+)
+
+// ParseFormat returns the format named by `s`. An empty string selects CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", CSVFormat:
+		return CSVFormat, nil
+	case JSONLFormat, SARIFFormat:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("Unknown warnings format '%s', expected csv, jsonl or sarif", s)
+}
+
+type entry struct {
+	bpFile   string
+	bpModule string
+	line     int
+	column   int
+	action   Action
+	message  string
+	category Category
+}
+
+type writer interface {
+	write(e *entry) error
+	close() error
+}
+
+func newWriter(out io.Writer, format Format) writer {
+	switch format {
+	case JSONLFormat:
+		return &jsonlWriter{out: json.NewEncoder(out)}
+	case SARIFFormat:
+		return &sarifWriter{out: out}
+	}
+	return newCSVWriter(out)
+}
+
+// csvWriter keeps the original format, without locations.
+type csvWriter struct {
+	out *csv.Writer
+}
+
+func newCSVWriter(out io.Writer) *csvWriter {
+	w := csv.NewWriter(out)
+	w.Write([]string{"BpFile", "BpModule", "WarningAction", "WarningMessage", "WarningCategory"})
+	w.Flush()
+	return &csvWriter{out: w}
+}
+
+func (w *csvWriter) write(e *entry) error {
+	w.out.Write([]string{e.bpFile, e.bpModule, string(e.action), e.message, string(e.category)})
+	w.out.Flush()
+	return w.out.Error()
+}
+
+func (w *csvWriter) close() error {
+	return nil
+}
+
+// jsonlWriter writes one JSON object per warning.
+type jsonlWriter struct {
+	out *json.Encoder
+}
+
+type jsonlEntry struct {
+	BpFile   string `json:"bp_file"`
+	BpModule string `json:"bp_module"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Action   Action `json:"action"`
+	Message  string `json:"message"`
+	Category string `json:"category"`
+}
+
+func (w *jsonlWriter) write(e *entry) error {
+	return w.out.Encode(jsonlEntry{
+		BpFile:   e.bpFile,
+		BpModule: e.bpModule,
+		Line:     e.line,
+		Column:   e.column,
+		Action:   e.action,
+		Message:  e.message,
+		Category: string(e.category),
+	})
+}
+
+func (w *jsonlWriter) close() error {
+	return nil
+}
+
+// sarifWriter collects the warnings into a single SARIF 2.1.0 log, which
+// is written out when the logger is closed. Ignored warnings are left out.
+type sarifWriter struct {
+	out     io.Writer
+	srcRoot string
+	entries []entry
+}
+
+func (w *sarifWriter) write(e *entry) error {
+	if e.action != IgnoreAction {
+		w.entries = append(w.entries, *e)
+	}
+	return nil
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID      string `json:"id"`
+	HelpURI string `json:"helpUri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri,omitempty"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// The base of the .bp file URIs, which are relative to the source directory
+const sarifSourceRoot = "%SRCROOT%"
+
+// sourceRootURI returns the file URI of the source directory, which SARIF
+// requires to end with a slash. It is empty when the directory is unknown.
+func (w *sarifWriter) sourceRootURI() string {
+	if w.srcRoot == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(w.srcRoot)
+	if err != nil {
+		return ""
+	}
+	dir = filepath.ToSlash(dir)
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return (&url.URL{Scheme: "file", Path: dir}).String()
+}
+
+func (w *sarifWriter) close() error {
+	// Results are ordered by location, whatever order the modules were
+	// visited in
+	sort.SliceStable(w.entries, func(i, j int) bool {
+		a, b := w.entries[i], w.entries[j]
+		if a.bpFile != b.bpFile {
+			return a.bpFile < b.bpFile
+		}
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.column < b.column
+	})
+
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "bob",
+				InformationURI: "https://github.com/ARM-software/bob-build",
+				Rules:          []sarifRule{},
+			},
+		},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			sarifSourceRoot: {URI: w.sourceRootURI()},
+		},
+		Results: []sarifResult{},
+	}
+
+	rules := map[Category]bool{}
+	for _, e := range w.entries {
+		if !rules[e.category] {
+			rules[e.category] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:      string(e.category),
+				HelpURI: categoryURL(e.category),
+			})
+		}
+
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(e.bpFile), URIBaseID: sarifSourceRoot},
+			},
+			LogicalLocations: []sarifLogicalLocation{{Name: e.bpModule, Kind: "module"}},
+		}
+		if e.line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: e.line, StartColumn: e.column}
+		}
+
+		level := "warning"
+		if e.action == ErrorAction {
+			level = "error"
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    string(e.category),
+			Level:     level,
+			Message:   sarifMessage{Text: e.message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w.out, string(data)+"\n")
+	return err
+}
@@ -0,0 +1,196 @@
+package warnings
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testLocator(bpFile string, bpModule string, property string) (int, int) {
+	if property == "srcs" {
+		return 12, 5
+	}
+	return 10, 1
+}
+
+func TestParseFormat(t *testing.T) {
+	for s, expected := range map[string]Format{
+		"":      CSVFormat,
+		"csv":   CSVFormat,
+		"jsonl": JSONLFormat,
+		"sarif": SARIFFormat,
+	} {
+		f, err := ParseFormat(s)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, f)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.EqualError(t, err, "Unknown warnings format 'xml', expected csv, jsonl or sarif")
+}
+
+func TestWarningJSONL(t *testing.T) {
+	const expected string = `{"bp_file":"A/build.bp","bp_module":"gen_table","line":10,"column":1,"action":"warning",` +
+		"\"message\":\"`bob_generate_source` should not be used. Use `bob_genrule` instead.\",\"category\":\"generate-rule\"}\n" +
+		`{"bp_file":"B/build.bp","bp_module":"gen_binary","line":12,"column":5,"action":"ignore",` +
+		"\"message\":\"Relative up-links in `srcs` are not allowed. Use `bob_filegroup` instead.\",\"category\":\"relative-up-link\"}\n"
+	var msg strings.Builder
+
+	wr := NewWithFormat(&msg, JSONLFormat, "GenerateRuleWarning:W")
+	wr.SetLocator(testLocator)
+
+	captureStderr(func() {
+		wr.Warn(GenerateRuleWarning, "A/build.bp", "gen_table")
+		wr.Warn(RelativeUpLinkWarning, "B/build.bp", "gen_binary")
+	})
+	assert.Nil(t, wr.Close())
+
+	assert.Equal(t, expected, msg.String())
+}
+
+func TestWarningJSONLWithoutLocation(t *testing.T) {
+	const expected string = `{"bp_file":"A/build.bp","bp_module":"gen_table","action":"ignore",` +
+		"\"message\":\"`bob_generate_source` should not be used. Use `bob_genrule` instead.\",\"category\":\"generate-rule\"}\n"
+	var msg strings.Builder
+
+	wr := NewWithFormat(&msg, JSONLFormat, "")
+	wr.Warn(GenerateRuleWarning, "A/build.bp", "gen_table")
+	assert.Nil(t, wr.Close())
+
+	assert.Equal(t, expected, msg.String())
+}
+
+func TestWarningSARIF(t *testing.T) {
+	const expected string = `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "bob",
+          "informationUri": "https://github.com/ARM-software/bob-build",
+          "rules": [
+            {
+              "id": "generate-rule",
+              "helpUri": "https://github.com/ARM-software/bob-build/tree/master/docs/warnings/generate-rule.md"
+            },
+            {
+              "id": "relative-up-link",
+              "helpUri": "https://github.com/ARM-software/bob-build/tree/master/docs/warnings/relative-up-link.md"
+            }
+          ]
+        }
+      },
+      "originalUriBaseIds": {
+        "%SRCROOT%": {
+          "uri": "file:///src/"
+        }
+      },
+      "results": [
+        {
+          "ruleId": "generate-rule",
+          "level": "warning",
+          "message": {
+            "text": "` + "`bob_generate_source`" + ` should not be used. Use ` + "`bob_genrule`" + ` instead."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "A/build.bp",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 10,
+                  "startColumn": 1
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "gen_table",
+                  "kind": "module"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "relative-up-link",
+          "level": "error",
+          "message": {
+            "text": "Relative up-links in ` + "`srcs`" + ` are not allowed. Use ` + "`bob_filegroup`" + ` instead."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "A/build.bp",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 12,
+                  "startColumn": 5
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "gen_binary",
+                  "kind": "module"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "relative-up-link",
+          "level": "error",
+          "message": {
+            "text": "Relative up-links in ` + "`srcs`" + ` are not allowed. Use ` + "`bob_filegroup`" + ` instead."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "B/build.bp",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 12,
+                  "startColumn": 5
+                }
+              },
+              "logicalLocations": [
+                {
+                  "name": "gen_binary",
+                  "kind": "module"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`
+	var msg strings.Builder
+
+	wr := NewWithFormat(&msg, SARIFFormat, "GenerateRuleWarning:W RelativeUpLinkWarning:E")
+	wr.SetLocator(testLocator)
+	wr.SetSourceRoot("/src")
+
+	// Results are sorted by location
+	captureStderr(func() {
+		wr.Warn(RelativeUpLinkWarning, "B/build.bp", "gen_binary")
+		wr.Warn(RelativeUpLinkWarning, "A/build.bp", "gen_binary")
+		wr.Warn(GenerateRuleWarning, "A/build.bp", "gen_table")
+	})
+
+	// Nothing is written until the logger is closed
+	assert.Equal(t, "", msg.String())
+	assert.Nil(t, wr.Close())
+
+	assert.Equal(t, expected, msg.String())
+}
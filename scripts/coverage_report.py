@@ -0,0 +1,166 @@
+#!/usr/bin/env python3
+
+
+"""
+Report the line coverage of modules built with `COVERAGE` enabled.
+
+The `module` command runs gcov (or `llvm-cov gcov`) on the notes of each
+object of a module, and records how many lines of each source were executed
+by the tests. Only the module's own sources are counted, so headers shared
+between modules are not reported more than once.
+
+The `summary` command merges the reports of every module into a single file,
+and prints the line coverage of each module.
+"""
+
+
+import argparse
+import json
+import os
+import re
+import shlex
+import subprocess
+import sys
+
+
+FILE_RE = re.compile(r"^File '(.*)'$")
+LINES_RE = re.compile(r"^Lines executed:\s*([0-9.]+)% of (\d+)$")
+
+
+def make_dir(d):
+    if d and not os.path.isdir(d):
+        os.makedirs(d, exist_ok=True)
+
+
+def parse_gcov_output(output):
+    """Return a dictionary mapping each file reported by gcov to a tuple of
+    (executed lines, total lines)."""
+    results = {}
+    current = None
+    for line in output.splitlines():
+        match = FILE_RE.match(line.strip())
+        if match:
+            current = match.group(1)
+            continue
+        match = LINES_RE.match(line.strip())
+        if match and current is not None:
+            total = int(match.group(2))
+            covered = int(round(float(match.group(1)) * total / 100))
+            results[current] = (covered, total)
+            current = None
+    return results
+
+
+def run_gcov(gcov, notes, source):
+    # gcov finds the notes, and the data written next to them, from the
+    # object name. `-n` stops it from writing `.gcov` files.
+    obj = os.path.splitext(notes)[0] + ".o"
+    cmd = shlex.split(gcov) + ["-n", "-o", obj, source]
+    try:
+        proc = subprocess.run(
+            cmd, stdout=subprocess.PIPE, stderr=subprocess.PIPE, check=False
+        )
+    except OSError as e:
+        sys.exit("Couldn't execute command '%s': %s" % (" ".join(cmd), e.strerror))
+    return parse_gcov_output(proc.stdout.decode("utf-8", errors="replace"))
+
+
+def same_file(a, b):
+    return os.path.realpath(a) == os.path.realpath(b)
+
+
+def module_report(args):
+    if len(args.notes) != len(args.sources):
+        sys.exit("Expected one source for each notes file")
+
+    files = []
+    for notes, source in zip(args.notes, args.sources):
+        covered, total = 0, 0
+        for name, (c, t) in run_gcov(args.gcov, notes, source).items():
+            if same_file(name, source):
+                covered, total = c, t
+                break
+        files.append({"file": source, "covered": covered, "lines": total})
+
+    report = {
+        "name": args.name,
+        "covered": sum(f["covered"] for f in files),
+        "lines": sum(f["lines"] for f in files),
+        "files": files,
+    }
+
+    make_dir(os.path.dirname(args.output))
+    with open(args.output, "w") as fp:
+        json.dump(report, fp, indent=2, sort_keys=True)
+
+
+def percentage(covered, lines):
+    return 100.0 * covered / lines if lines else 100.0
+
+
+def summary_report(args):
+    modules = []
+    for path in args.reports:
+        with open(path) as fp:
+            report = json.load(fp)
+        # Modules without C or C++ sources have nothing to report
+        if report["lines"] == 0:
+            continue
+        modules.append(
+            {
+                "name": report["name"],
+                "covered": report["covered"],
+                "lines": report["lines"],
+                "percent": round(percentage(report["covered"], report["lines"]), 2),
+            }
+        )
+
+    modules.sort(key=lambda m: m["name"])
+    covered = sum(m["covered"] for m in modules)
+    lines = sum(m["lines"] for m in modules)
+    summary = {
+        "modules": modules,
+        "covered": covered,
+        "lines": lines,
+        "percent": round(percentage(covered, lines), 2),
+    }
+
+    make_dir(os.path.dirname(args.output))
+    with open(args.output, "w") as fp:
+        json.dump(summary, fp, indent=2, sort_keys=True)
+
+    width = max([len(m["name"]) for m in modules] + [len("Total")])
+    for m in modules:
+        print(
+            "%-*s %6.2f%% (%d/%d lines)"
+            % (width, m["name"], m["percent"], m["covered"], m["lines"])
+        )
+    print("%-*s %6.2f%% (%d/%d lines)" % (width, "Total", summary["percent"], covered, lines))
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    subparsers = parser.add_subparsers(dest="command")
+    subparsers.required = True
+
+    module = subparsers.add_parser("module", help="Report the coverage of a module")
+    module.add_argument("--gcov", required=True, help="gcov command to run")
+    module.add_argument("--name", required=True, help="Name of the module")
+    module.add_argument("--output", required=True, help="JSON report to write")
+    module.add_argument(
+        "--sources", nargs="*", default=[], help="Source of each notes file"
+    )
+    module.add_argument("notes", nargs="*", help="Notes (.gcno) files of the module")
+    module.set_defaults(func=module_report)
+
+    summary = subparsers.add_parser("summary", help="Merge the module reports")
+    summary.add_argument("--output", required=True, help="JSON summary to write")
+    summary.add_argument("reports", nargs="*", help="Reports of each module")
+    summary.set_defaults(func=summary_report)
+
+    args = parser.parse_args()
+    args.func(args)
+
+
+if __name__ == "__main__":
+    main()
@@ -3,7 +3,9 @@
 
 import argparse
 import hashlib
+import json
 import os
+import subprocess
 import sys
 
 # The config system is in the directory above, so add it to the python path
@@ -32,6 +34,7 @@ def hash_env():
         # bob-build
         "BOB_ALWAYS_LINK_SHARED_LIBS",
         "BOB_BOOTSTRAP_VERSION",
+        "BOB_COMPILE_COMMANDS_FILE",
         "BOB_CONFIG_OPTS",
         "BOB_CONFIG_PLUGIN_OPTS",
         "BOB_CPUPROFILE",
@@ -89,10 +92,70 @@ def hash_env():
     return m.hexdigest()
 
 
-def write_env_hash(filename):
-    """Write a hash of the current environment to the named file."""
+def hermetic_compilers(config):
+    """Return the C compiler of each hermetic toolchain in the configuration,
+    keyed by its target type, e.g. `target` or `host`."""
+
+    def get(tgt, name):
+        key = "{}_{}".format(tgt, name)
+        if key not in config and tgt != "host":
+            # Target variants fall back to the target options
+            key = "target_" + name
+        return config.get(key, {}).get("value", "")
+
+    compilers = {}
+    for key in config:
+        if not key.endswith("_toolchain_root"):
+            continue
+        tgt = key[: -len("_toolchain_root")]
+        root = get(tgt, "toolchain_root")
+        if not root:
+            continue
+
+        if get(tgt, "toolchain_clang"):
+            cc = get(tgt, "clang_prefix") + get(tgt, "clang_cc_binary")
+        elif get(tgt, "toolchain_gnu"):
+            cc = get(tgt, "gnu_prefix") + get(tgt, "gnu_cc_binary")
+        else:
+            continue
+
+        # As in Bob, tools named without a directory are in the root's bin
+        if os.sep not in cc:
+            cc = os.path.join(root, "bin", cc)
+        compilers[tgt] = cc
+    return compilers
+
+
+def compiler_version(cc):
+    """Return the version output of a compiler, which is empty if it cannot
+    be run. Bob will report the error."""
+    try:
+        return subprocess.check_output(
+            [cc, "--version"], stderr=subprocess.STDOUT
+        ).decode("utf-8", "replace")
+    except (OSError, subprocess.CalledProcessError):
+        return ""
+
+
+def hash_toolchains(config_json):
+    """Hash the versions of the hermetic toolchains, so that upgrading one in
+    place regenerates build.ninja."""
+    with open(config_json, "r") as fp:
+        config = json.load(fp)
+
+    m = hashlib.sha256()
+    for tgt, cc in sorted(hermetic_compilers(config).items()):
+        m.update("{}={}\n{}\n".format(tgt, cc, compiler_version(cc)).encode("utf-8"))
+    return m.hexdigest()
+
+
+def write_env_hash(filename, config_json=None):
+    """Write a hash of the current environment to the named file, followed by
+    the hash of the hermetic toolchains' versions if a configuration is given."""
     with utils.open_and_write_if_changed(filename) as fp:
         fp.write(hash_env())
+        if config_json:
+            fp.write("\n" + hash_toolchains(config_json))
 
 
 def test_hash_env_relevant():
@@ -134,14 +197,37 @@ def test_hash_env_irrelevant():
     assert org_hash == hash_env()
 
 
+def test_hermetic_compilers():
+    """Test the compilers of hermetic toolchains are found in the root"""
+    config = {
+        "target_toolchain_root": {"value": "/opt/tc"},
+        "target_toolchain_gnu": {"value": True},
+        "target_gnu_prefix": {"value": "aarch64-linux-gnu-"},
+        "target_gnu_cc_binary": {"value": "gcc"},
+        "arm32_toolchain_root": {"value": "/opt/tc32"},
+        "arm32_gnu_prefix": {"value": "/opt/bin/arm-linux-gnueabihf-"},
+        "host_toolchain_root": {"value": ""},
+        "host_toolchain_gnu": {"value": True},
+    }
+
+    assert hermetic_compilers(config) == {
+        "target": "/opt/tc/bin/aarch64-linux-gnu-gcc",
+        "arm32": "/opt/bin/arm-linux-gnueabihf-gcc",
+    }
+
+
 def main():
     parser = argparse.ArgumentParser()
     parser.add_argument(
         "output", help="Output file to write containing environment hash"
     )
+    parser.add_argument(
+        "--config-json",
+        help="Configuration whose hermetic toolchain versions are hashed",
+    )
     args = parser.parse_args()
 
-    write_env_hash(args.output)
+    write_env_hash(args.output, args.config_json)
 
 
 if __name__ == "__main__":
@@ -0,0 +1,114 @@
+#!/usr/bin/env python3
+
+
+"""
+Write the manifest of the installed files, as JSON or as an mtree file.
+
+Each entry gives the path of an installed file, followed by its mode, owner
+and group, separated by colons. An empty mode is read from the installed
+file, and the owner and group default to root. A path given as `@file` is
+read from the second line of the file, as written by strip.py for debug
+files named after a build-id. The entry is skipped when there is none.
+"""
+
+
+import argparse
+import hashlib
+import json
+import os
+import stat
+
+
+def parse_entry(entry):
+    path, mode, owner, group = entry.rsplit(":", 3)
+    if path.startswith("@"):
+        with open(path[1:]) as f:
+            lines = f.read().split("\n")
+        path = lines[1] if len(lines) > 1 and lines[0] else None
+    return path, mode, owner or "root", group or "root"
+
+
+def sha256(path):
+    h = hashlib.sha256()
+    with open(path, "rb") as f:
+        for chunk in iter(lambda: f.read(65536), b""):
+            h.update(chunk)
+    return h.hexdigest()
+
+
+def describe(entry, root):
+    path, mode, owner, group = parse_entry(entry)
+    if path is None:
+        return None
+    st = os.lstat(path)
+    desc = {
+        "path": os.path.relpath(path, root),
+        "owner": owner,
+        "group": group,
+    }
+    if stat.S_ISLNK(st.st_mode):
+        desc["type"] = "link"
+        desc["target"] = os.readlink(path)
+    else:
+        desc["type"] = "file"
+        desc["mode"] = mode or "%04o" % stat.S_IMODE(st.st_mode)
+        desc["size"] = st.st_size
+        desc["sha256"] = sha256(path)
+    return desc
+
+
+def mtree_escape(path):
+    """Escape the characters which mtree does not allow in a path."""
+    escaped = []
+    for c in path.encode("utf-8"):
+        if c <= 0x20 or c >= 0x7F or chr(c) in "\\#":
+            escaped.append("\\%03o" % c)
+        else:
+            escaped.append(chr(c))
+    return "".join(escaped)
+
+
+def mtree(files):
+    lines = ["#mtree"]
+    for f in files:
+        attrs = ["./" + mtree_escape(f["path"]), "type=" + f["type"]]
+        if f["type"] == "link":
+            attrs.append("link=" + mtree_escape(f["target"]))
+        else:
+            attrs.append("mode=" + f["mode"])
+        attrs += ["uname=" + f["owner"], "gname=" + f["group"]]
+        if f["type"] == "file":
+            attrs += ["size=%d" % f["size"], "sha256digest=" + f["sha256"]]
+        lines.append(" ".join(attrs))
+    return "\n".join(lines) + "\n"
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--format", choices=["json", "mtree"], required=True)
+    parser.add_argument(
+        "--root", required=True, help="Directory the manifest paths are relative to"
+    )
+    parser.add_argument(
+        "--entries", required=True, help="File listing the installed files"
+    )
+    parser.add_argument("-o", "--output", required=True)
+    args = parser.parse_args()
+
+    with open(args.entries) as f:
+        entries = f.read().split()
+
+    files = [describe(e, args.root) for e in entries]
+    files = sorted((f for f in files if f), key=lambda f: f["path"])
+
+    if args.format == "json":
+        content = json.dumps({"files": files}, indent=2, sort_keys=True) + "\n"
+    else:
+        content = mtree(files)
+
+    with open(args.output, "w") as f:
+        f.write(content)
+
+
+if __name__ == "__main__":
+    main()
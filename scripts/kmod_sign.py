@@ -0,0 +1,60 @@
+#!/usr/bin/env python3
+
+
+import argparse
+import logging
+import os
+import subprocess
+import sys
+
+logger = logging.getLogger(__name__)
+
+
+def parse_args():
+    logging.basicConfig(format="%(levelname)s: %(message)s", level=logging.WARNING)
+
+    parser = argparse.ArgumentParser(
+        description="Sign a kernel module with the kernel's sign-file tool"
+    )
+    parser.add_argument("input", help="Kernel module to sign")
+    parser.add_argument(
+        "--output", "-o", required=True, help="Signed kernel module to write"
+    )
+    parser.add_argument(
+        "--kernel", "-k", metavar="KDIR", required=True, help="Kernel directory"
+    )
+    parser.add_argument("--hash", required=True, help="Hash algorithm to sign with")
+    parser.add_argument(
+        "--key", required=True, help="Private key file or PKCS#11 URI to sign with"
+    )
+    parser.add_argument(
+        "--cert", required=True, help="X.509 certificate matching the key"
+    )
+    return parser.parse_args()
+
+
+def main():
+    args = parse_args()
+
+    # sign-file is a host tool built with the kernel, which also reads
+    # KBUILD_SIGN_PIN from the environment for encrypted keys.
+    sign_file = os.path.join(os.path.abspath(args.kernel), "scripts", "sign-file")
+    if not os.path.isfile(sign_file):
+        msg = "%s not found. make modules_prepare needs to be run"
+        logger.error(msg, sign_file)
+        sys.exit(1)
+
+    output_dir = os.path.dirname(args.output)
+    if output_dir and not os.path.isdir(output_dir):
+        os.makedirs(output_dir)
+
+    cmd = [sign_file, args.hash, args.key, args.cert, args.input, args.output]
+    try:
+        subprocess.check_call(cmd)
+    except subprocess.CalledProcessError as e:
+        logger.error("Command failed: %s", str(e.cmd))
+        sys.exit(e.returncode)
+
+
+if __name__ == "__main__":
+    main()
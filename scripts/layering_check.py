@@ -0,0 +1,115 @@
+#!/usr/bin/env python3
+
+
+"""
+Check that a source only includes the headers of the libraries its module
+directly depends on, as Bazel's `layering_check` does.
+
+The source is preprocessed with `-H`, which makes GCC and Clang print every
+header they include, indented by its include depth. This gives each
+include's includer. Only the includes of files owned by the module itself
+are checked. This covers its sources and any header not in the `hdrs` of a
+dependency. Headers including other headers are checked when their own
+library is.
+"""
+
+
+import argparse
+import os
+import re
+import subprocess
+import sys
+
+
+INCLUDE_RE = re.compile(r"^(\.+) (.*)$")
+
+
+def parse_owners(hdrs):
+    owners = {}
+    for h in hdrs:
+        owner, _, path = h.partition("=")
+        owners[os.path.realpath(path)] = owner
+    return owners
+
+
+def includes(output):
+    """Yield the (includer, header) pairs of a preprocessor's `-H` output.
+    The includer is None for the headers included by the source."""
+    stack = []
+    for line in output.splitlines():
+        match = INCLUDE_RE.match(line)
+        if not match:
+            continue
+        depth = len(match.group(1))
+        header = match.group(2)
+        del stack[depth - 1 :]
+        yield (stack[-1] if stack else None), header
+        stack.append(header)
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--name", required=True, help="Name of the module")
+    parser.add_argument(
+        "--deps", nargs="*", default=[], help="Direct dependencies of the module"
+    )
+    parser.add_argument(
+        "--hdrs",
+        nargs="*",
+        default=[],
+        help="Headers of the module's dependencies, as <library>=<path>",
+    )
+    parser.add_argument("--stamp", required=True, help="File to touch on success")
+    parser.add_argument("command", nargs="+", help="Command compiling the source")
+    args = parser.parse_args()
+
+    owners = parse_owners(args.hdrs)
+    source = args.command[-1]
+
+    cmd = args.command + ["-E", "-H", "-o", os.devnull]
+    try:
+        proc = subprocess.run(
+            cmd, stdout=subprocess.PIPE, stderr=subprocess.PIPE, check=False
+        )
+    except OSError as e:
+        sys.exit("Couldn't execute command '%s': %s" % (" ".join(cmd), e.strerror))
+
+    output = proc.stderr.decode("utf-8", errors="replace")
+    if proc.returncode != 0:
+        sys.stderr.write(output)
+        sys.exit(proc.returncode)
+
+    errors = []
+    for includer, header in includes(output):
+        if includer is not None and os.path.realpath(includer) in owners:
+            continue
+        owner = owners.get(os.path.realpath(header))
+        if owner is None or owner in args.deps:
+            continue
+        error = "%s: error: %s includes '%s' from '%s', which is not a direct dependency" % (
+            args.name,
+            includer or source,
+            header,
+            owner,
+        )
+        if error not in errors:
+            errors.append(error)
+
+    if errors:
+        for e in errors:
+            print(e, file=sys.stderr)
+        print(
+            "%s: add the libraries to `deps` to include their headers" % args.name,
+            file=sys.stderr,
+        )
+        sys.exit(1)
+
+    stamp_dir = os.path.dirname(args.stamp)
+    if stamp_dir and not os.path.isdir(stamp_dir):
+        os.makedirs(stamp_dir, exist_ok=True)
+    with open(args.stamp, "w"):
+        pass
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,213 @@
+#!/usr/bin/env python3
+
+
+"""
+Write a pkg-config or CMake package file for an installed library.
+
+Paths are written relative to the location of the package file, so that the
+installed files can be moved together. Include directories are written as
+absolute paths, as headers are not installed with the library.
+"""
+
+
+import argparse
+import os
+
+
+HEADER = "# Generated by Bob from build.bp. Do not edit.\n"
+
+
+def relative(path, start, var):
+    rel = os.path.relpath(path, start)
+    if rel == ".":
+        return var
+    return var + "/" + rel
+
+
+def split_cflags(cflags):
+    """Split the compile flags into include directories, system include
+    directories and other options."""
+    includes = []
+    system_includes = []
+    options = []
+    flags = iter(cflags)
+    for f in flags:
+        if f == "-isystem":
+            system_includes.append(os.path.abspath(next(flags)))
+        elif f.startswith("-I"):
+            includes.append(os.path.abspath(f[2:]))
+        else:
+            options.append(f)
+    return includes, system_includes, options
+
+
+def join_cflags(cflags):
+    """Join the compile flags, keeping `-isystem` with its directory."""
+    joined = []
+    flags = iter(cflags)
+    for f in flags:
+        if f == "-isystem":
+            joined.append(f + " " + os.path.abspath(next(flags)))
+        elif f.startswith("-I"):
+            joined.append("-I" + os.path.abspath(f[2:]))
+        else:
+            joined.append(f)
+    return " ".join(joined)
+
+
+def link_flag(library):
+    """Return the flag linking a library from the library directory."""
+    base = os.path.basename(library)
+    stem, _ = os.path.splitext(base)
+    if stem.startswith("lib"):
+        return "-l" + stem[len("lib") :]
+    return "${libdir}/" + base
+
+
+def pkg_config(args, out_dir):
+    libdir = os.path.dirname(os.path.abspath(args.library))
+    lib_dirs = [
+        "-L" + relative(os.path.abspath(d), out_dir, "${pcfiledir}")
+        for d in args.lib_dir
+    ]
+    libs = ["-L${libdir}", link_flag(args.library)] + args.ldflag
+    deps = lib_dirs + args.lib
+
+    lines = [
+        HEADER,
+        "libdir=" + relative(libdir, out_dir, "${pcfiledir}"),
+        "",
+        "Name: " + args.name,
+        "Description: " + args.description,
+        "Version: " + args.version,
+    ]
+
+    # Consumers of a static library also link its dependencies
+    private = ".private" if args.kind == "shared" else ""
+    if args.requires:
+        lines.append("Requires%s: %s" % (private, ", ".join(args.requires)))
+    if args.cflag:
+        lines.append("Cflags: " + join_cflags(args.cflag))
+    if private:
+        lines.append("Libs: " + " ".join(libs))
+        if deps:
+            lines.append("Libs.private: " + " ".join(deps))
+    else:
+        lines.append("Libs: " + " ".join(libs + deps))
+    return "\n".join(lines) + "\n"
+
+
+def cmake_list(values):
+    return '"%s"' % ";".join(values)
+
+
+def cmake(args, out_dir):
+    target = "%s::%s" % (args.name, args.name)
+    libdir = os.path.dirname(os.path.abspath(args.library))
+    includes, system_includes, options = split_cflags(args.cflag)
+
+    props = [
+        (
+            "IMPORTED_LOCATION",
+            '"${_IMPORT_DIR}/%s"' % os.path.basename(args.library),
+        ),
+    ]
+    if includes or system_includes:
+        props.append(
+            ("INTERFACE_INCLUDE_DIRECTORIES", cmake_list(includes + system_includes))
+        )
+    if system_includes:
+        props.append(
+            ("INTERFACE_SYSTEM_INCLUDE_DIRECTORIES", cmake_list(system_includes))
+        )
+    if options:
+        props.append(("INTERFACE_COMPILE_OPTIONS", cmake_list(options)))
+    if args.ldflag:
+        props.append(("INTERFACE_LINK_OPTIONS", cmake_list(args.ldflag)))
+
+    # Consumers of a shared library do not link its dependencies
+    lines = [HEADER]
+    if args.kind == "static":
+        if args.requires:
+            lines.append("include(CMakeFindDependencyMacro)")
+            for r in args.requires:
+                lines.append("find_dependency(%s)" % r)
+            lines.append("")
+        lib_dirs = [
+            relative(os.path.abspath(d), libdir, "${_IMPORT_DIR}") for d in args.lib_dir
+        ]
+        if lib_dirs:
+            props.append(("INTERFACE_LINK_DIRECTORIES", cmake_list(lib_dirs)))
+        libs = ["%s::%s" % (r, r) for r in args.requires] + args.lib
+        if libs:
+            props.append(("INTERFACE_LINK_LIBRARIES", cmake_list(libs)))
+
+    lines += [
+        'get_filename_component(_IMPORT_DIR "%s" ABSOLUTE)'
+        % relative(libdir, out_dir, "${CMAKE_CURRENT_LIST_DIR}"),
+        "",
+        "if(NOT TARGET %s)" % target,
+        "    add_library(%s %s IMPORTED)" % (target, args.kind.upper()),
+        "    set_target_properties(%s PROPERTIES" % target,
+    ]
+    for name, value in props:
+        lines.append("        %s %s" % (name, value))
+    lines += [
+        "    )",
+        "endif()",
+        "",
+        "unset(_IMPORT_DIR)",
+    ]
+    return "\n".join(lines) + "\n"
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--format", choices=["pkg-config", "cmake"], required=True)
+    parser.add_argument("--name", required=True, help="Name of the package")
+    parser.add_argument("--version", required=True, help="Version of the package")
+    parser.add_argument("--description", required=True)
+    parser.add_argument(
+        "--library", required=True, help="Path of the installed library"
+    )
+    parser.add_argument("--kind", choices=["static", "shared"], required=True)
+    parser.add_argument(
+        "--cflag", action="append", default=[], help="Exported compile flag"
+    )
+    parser.add_argument(
+        "--ldflag", action="append", default=[], help="Exported link flag"
+    )
+    parser.add_argument(
+        "--requires",
+        action="append",
+        default=[],
+        help="Package of a library dependency",
+    )
+    parser.add_argument(
+        "--lib",
+        action="append",
+        default=[],
+        help="Flag linking a library dependency without a package",
+    )
+    parser.add_argument(
+        "--lib-dir",
+        action="append",
+        default=[],
+        help="Install directory of a library dependency",
+    )
+    parser.add_argument("-o", "--output", required=True)
+    args = parser.parse_args()
+
+    out_dir = os.path.dirname(os.path.abspath(args.output))
+    if args.format == "pkg-config":
+        content = pkg_config(args, out_dir)
+    else:
+        content = cmake(args, out_dir)
+
+    os.makedirs(out_dir, exist_ok=True)
+    with open(args.output, "w") as f:
+        f.write(content)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,190 @@
+#!/usr/bin/env python3
+
+
+import argparse
+import errno
+import os
+import subprocess
+import sys
+import time
+import xml.etree.ElementTree as ET
+
+
+"""
+Run a single test binary on behalf of a `bob_test` module.
+
+A passing run is recorded in a stamp file, and a JUnit-style XML
+report is written alongside it so CI systems can collect the results. The
+script exits with the exit code of the test, so Ninja will only consider the
+test up to date once it has passed.
+
+Sharded tests are told which shard to run through the same environment
+variables as Bazel and GoogleTest use.
+"""
+
+
+def make_dir(d):
+    try:
+        os.makedirs(d)
+    except OSError as e:
+        # Ignore errors if the dir already exists. Any other error number is
+        # unexpected, so re-raise.
+        if e.errno != errno.EEXIST:
+            raise
+
+
+def run_test(cmd, env, timeout):
+    try:
+        proc = subprocess.run(
+            cmd,
+            env=env,
+            stdout=subprocess.PIPE,
+            stderr=subprocess.STDOUT,
+            timeout=timeout,
+        )
+        return proc.returncode, proc.stdout.decode("utf-8", errors="replace")
+    except subprocess.TimeoutExpired as e:
+        output = (e.output or b"").decode("utf-8", errors="replace")
+        return 1, output + "Test timed out after %d seconds\n" % timeout
+    except OSError as e:
+        return 1, "Couldn't execute command '%s': %s" % (" ".join(cmd), e.strerror)
+
+
+def get_test_env(args):
+    env = dict(os.environ)
+
+    for var in args.env:
+        name, _, value = var.partition("=")
+        env[name] = value
+
+    if args.total_shards > 1:
+        for prefix in ["TEST", "GTEST"]:
+            env[prefix + "_TOTAL_SHARDS"] = str(args.total_shards)
+            env[prefix + "_SHARD_INDEX"] = str(args.shard_index)
+
+    return env
+
+
+def write_junit_xml(fname, name, duration, returncode, output):
+    suite = ET.Element(
+        "testsuite",
+        name=name,
+        tests="1",
+        failures="0" if returncode == 0 else "1",
+        errors="0",
+        time="%.3f" % duration,
+    )
+    case = ET.SubElement(
+        suite, "testcase", name=name, classname=name, time="%.3f" % duration
+    )
+    if returncode != 0:
+        failure = ET.SubElement(
+            case, "failure", message="exited with code %d" % returncode
+        )
+        failure.text = output
+    ET.SubElement(case, "system-out").text = output
+
+    root = ET.Element("testsuites")
+    root.append(suite)
+
+    make_dir(os.path.dirname(fname))
+    ET.ElementTree(root).write(fname, encoding="utf-8", xml_declaration=True)
+
+
+def write_stamp(fname, returncode):
+    """Write the stamp if the test passed. Otherwise remove any stamp of an
+    earlier run, so the test is not considered up to date."""
+    if returncode != 0:
+        try:
+            os.remove(fname)
+        except OSError as e:
+            if e.errno != errno.ENOENT:
+                raise
+        return
+
+    make_dir(os.path.dirname(fname))
+    with open(fname, "w") as fp:
+        fp.write("PASS\n")
+
+
+def test_write_stamp(tmp_path):
+    """Test the stamp is only left in place by a passing run"""
+    stamp = str(tmp_path / "tests" / "t.stamp")
+
+    write_stamp(stamp, 0)
+    assert os.path.exists(stamp)
+
+    write_stamp(stamp, 1)
+    assert not os.path.exists(stamp)
+
+    # No stamp to remove
+    write_stamp(stamp, 1)
+    assert not os.path.exists(stamp)
+
+
+def parse_args():
+    parser = argparse.ArgumentParser(description="Run a bob_test binary")
+
+    parser.add_argument("--name", required=True, help="Name of the test")
+    parser.add_argument(
+        "--stamp", required=True, help="Stamp file recording the test result"
+    )
+    parser.add_argument(
+        "--junit-xml", required=True, help="JUnit-style XML result to write"
+    )
+    parser.add_argument(
+        "--timeout", type=int, help="Number of seconds after which the test is killed"
+    )
+    parser.add_argument(
+        "--attempts",
+        type=int,
+        default=1,
+        help="Number of times to run the test before considering it failed",
+    )
+    parser.add_argument(
+        "--shard-index", type=int, default=0, help="Index of the shard to run"
+    )
+    parser.add_argument(
+        "--total-shards", type=int, default=1, help="Number of shards of the test"
+    )
+    parser.add_argument(
+        "--env",
+        action="append",
+        default=[],
+        help="Environment variable to set for the test, as NAME=value",
+    )
+    parser.add_argument("test", help="Test binary to run")
+    parser.add_argument(
+        "args", nargs=argparse.REMAINDER, help="Arguments passed to the test"
+    )
+
+    return parser.parse_args()
+
+
+def main():
+    args = parse_args()
+
+    cmd = [os.path.abspath(args.test)] + args.args
+    env = get_test_env(args)
+
+    start = time.time()
+    for _ in range(max(args.attempts, 1)):
+        returncode, output = run_test(cmd, env, args.timeout)
+        if returncode == 0:
+            break
+    duration = time.time() - start
+
+    write_junit_xml(args.junit_xml, args.name, duration, returncode, output)
+    write_stamp(args.stamp, returncode)
+
+    if returncode != 0:
+        sys.stdout.write(output)
+        sys.stderr.write(
+            "Error: Test %s failed with exit code %d\n" % (args.name, returncode)
+        )
+        # Exit with a non-zero code even if the test was killed by a signal
+        sys.exit(returncode if returncode > 0 else 1)
+
+
+if __name__ == "__main__":
+    main()
@@ -3,7 +3,9 @@
 
 import argparse
 import errno
+import mmap
 import os
+import struct
 import subprocess
 import sys
 
@@ -33,13 +35,88 @@ def run(cmd):
         sys.exit(1)
 
 
-def elf_create_debug_info(fname, dbg, tool):
+# Section type and note type holding the GNU build-id
+SHT_NOTE = 7
+NT_GNU_BUILD_ID = 3
+
+
+def align4(n):
+    return (n + 3) & ~3
+
+
+def elf_build_id(fname):
+    """Return the GNU build-id of an ELF file as a hex string, or None."""
+    with open(fname, "rb") as f:
+        data = mmap.mmap(f.fileno(), 0, access=mmap.ACCESS_READ)
+
+    if data[:4] != b"\x7fELF":
+        return None
+    is64 = data[4] == 2
+    endian = "<" if data[5] == 1 else ">"
+
+    if is64:
+        (shoff,) = struct.unpack_from(endian + "Q", data, 0x28)
+        shentsize, shnum = struct.unpack_from(endian + "HH", data, 0x3A)
+    else:
+        (shoff,) = struct.unpack_from(endian + "I", data, 0x20)
+        shentsize, shnum = struct.unpack_from(endian + "HH", data, 0x2E)
+
+    for i in range(shnum):
+        sh = shoff + i * shentsize
+        (sh_type,) = struct.unpack_from(endian + "I", data, sh + 4)
+        if sh_type != SHT_NOTE:
+            continue
+        if is64:
+            offset, size = struct.unpack_from(endian + "QQ", data, sh + 0x18)
+        else:
+            offset, size = struct.unpack_from(endian + "II", data, sh + 0x10)
+
+        pos = offset
+        while pos + 12 <= offset + size:
+            namesz, descsz, note_type = struct.unpack_from(endian + "III", data, pos)
+            pos += 12
+            name = data[pos : pos + namesz]
+            pos += align4(namesz)
+            desc = data[pos : pos + descsz]
+            pos += align4(descsz)
+            if note_type == NT_GNU_BUILD_ID and name == b"GNU\0":
+                return desc.hex()
+
+    return None
+
+
+def build_id_debug_file(build_id_dir, build_id):
+    """Return the debug file of a build-id, in the layout GDB searches."""
+    return os.path.join(
+        build_id_dir, ".build-id", build_id[:2], build_id[2:] + ".debug"
+    )
+
+
+def read_build_id_file(fname):
+    """Return the build-id and the debug file recorded by --build-id-file,
+    which are None when the file does not exist or records no build-id."""
+    try:
+        with open(fname) as f:
+            lines = f.read().split("\n")
+    except IOError as e:
+        if e.errno != errno.ENOENT:
+            raise
+        return None, None
+    if len(lines) < 2 or not lines[0]:
+        return None, None
+    return lines[0], lines[1]
+
+
+def elf_create_debug_info(fname, dbg, tool, compress):
     # Retain the build-id in the debug object
-    cmd = [tool, "--only-keep-debug", fname, dbg]
+    cmd = [tool, "--only-keep-debug"]
+    if compress:
+        cmd.append("--compress-debug-sections=zlib")
+    cmd.extend([fname, dbg])
     run(cmd)
 
 
-def macho_create_debug_info(fname, dbg, tool):
+def macho_create_debug_info(fname, dbg, tool, compress):
     cmd = [tool, fname, "-o", dbg]
     run(cmd)
 
@@ -70,7 +147,26 @@ def parse_args():
         default=False,
         help="Strip library of unnecessary symbols",
     )
-    parser.add_argument("--debug-file", default=None, help="File to keep debug info in")
+    debug = parser.add_mutually_exclusive_group()
+    debug.add_argument("--debug-file", default=None, help="File to keep debug info in")
+    debug.add_argument(
+        "--build-id-dir",
+        default=None,
+        help="Directory to keep debug info in, named after the GNU build-id "
+        "as .build-id/xx/yyyy.debug",
+    )
+    parser.add_argument(
+        "--build-id-file",
+        default=None,
+        help="File to write the GNU build-id and the path of the debug file "
+        "to, when using --build-id-dir",
+    )
+    parser.add_argument(
+        "--compress-debug-sections",
+        action="store_true",
+        default=False,
+        help="Compress the sections of the debug info (ELF only)",
+    )
     parser.add_argument(
         "--format",
         action="store",
@@ -100,6 +196,11 @@ def parse_args():
 
     args = parser.parse_args()
 
+    if args.build_id_dir and args.format != "elf":
+        parser.error("--build-id-dir is only supported for ELF files")
+    if args.build_id_file and not args.build_id_dir:
+        parser.error("--build-id-file requires --build-id-dir")
+
     return args
 
 
@@ -120,12 +221,44 @@ def main():
     debug_file = args.debug_file
     # GNU debuglink sections are for ELF objects; archives (.a) don't support
     # them and objcopy will fail when trying to add one to each member.
-    if args.format == "elf" and debug_file and args.input.endswith(".a"):
+    is_archive = args.format == "elf" and args.input.endswith(".a")
+    if is_archive:
         debug_file = None
 
+    build_id = None
+    if args.build_id_dir and not is_archive:
+        build_id = elf_build_id(args.input)
+        if build_id is None:
+            sys.stderr.write(
+                "Error: %s has no GNU build-id. Link it with -Wl,--build-id\n"
+                % args.input
+            )
+            sys.exit(1)
+        debug_file = build_id_debug_file(args.build_id_dir, build_id)
+
+    if args.build_id_file:
+        # The debug file is not a Ninja output, as its name depends on the
+        # build-id, so remove the one written for a previous build-id
+        old_debug_file = read_build_id_file(args.build_id_file)[1]
+        if old_debug_file and old_debug_file != debug_file:
+            try:
+                os.remove(old_debug_file)
+                os.rmdir(os.path.dirname(old_debug_file))
+            except OSError as e:
+                # The directory is kept while it holds other debug files
+                if e.errno not in (errno.ENOENT, errno.ENOTEMPTY):
+                    raise
+
+        # Archives have no build-id, so record an empty one
+        with open(args.build_id_file, "w") as f:
+            if build_id:
+                f.write(build_id + "\n" + debug_file + "\n")
+
     if debug_file:
-        make_dir(os.path.dirname(args.debug_file))
-        create_debug_info(args.input, debug_file, debug_info_tool)
+        make_dir(os.path.dirname(debug_file))
+        create_debug_info(
+            args.input, debug_file, debug_info_tool, args.compress_debug_sections
+        )
 
     write_output(args.input, args.output, debug_file, args.strip, strip_tool)
 
@@ -0,0 +1,57 @@
+#!/usr/bin/env python3
+
+
+"""
+Write the index of the build-ids of the binaries whose debug information is
+installed into an install group.
+
+Each entry gives the path of an installed binary, followed by the file
+strip.py recorded its build-id and debug file in, separated by a colon.
+Binaries without a build-id, such as static archives, are omitted.
+"""
+
+
+import argparse
+import json
+import os
+
+
+def describe(entry, root):
+    installed, build_id_file = entry.rsplit(":", 1)
+    with open(build_id_file) as f:
+        lines = f.read().split("\n")
+    if len(lines) < 2 or not lines[0]:
+        return None, None
+
+    build_id, debug_file = lines[0], lines[1]
+    return build_id, {
+        "file": os.path.relpath(installed, root),
+        "debug_file": os.path.relpath(debug_file, root),
+    }
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("--root", required=True, help="Install root")
+    parser.add_argument(
+        "--entries", required=True, help="File listing the installed binaries"
+    )
+    parser.add_argument("-o", "--output", required=True, help="Output file")
+    args = parser.parse_args()
+
+    with open(args.entries) as f:
+        entries = f.read().split()
+
+    index = {}
+    for entry in entries:
+        build_id, desc = describe(entry, args.root)
+        if build_id is not None:
+            index[build_id] = desc
+
+    with open(args.output, "w") as f:
+        json.dump(index, f, indent=4, sort_keys=True)
+        f.write("\n")
+
+
+if __name__ == "__main__":
+    main()